@@ -0,0 +1,264 @@
+package engine
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// BlobStore 基于本地目录的大值存储，每个 BlobStore 独占一个临时子目录，Close 时整体删除
+type BlobStore struct {
+	mu     sync.Mutex
+	dir    string
+	size   int64
+	closed bool
+}
+
+// NewBlobStore 在 dir 下创建独占的子目录作为存储，dir 为空时使用系统临时目录
+func NewBlobStore(dir string) (*BlobStore, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, &DataContextError{Type: "blob_store_failed", Message: err.Error(), Cause: err}
+		}
+	}
+	path, err := os.MkdirTemp(dir, "kflow-blobs-*")
+	if err != nil {
+		return nil, &DataContextError{Type: "blob_store_failed", Message: err.Error(), Cause: err}
+	}
+	return &BlobStore{dir: path}, nil
+}
+
+// Dir 返回存储目录
+func (s *BlobStore) Dir() string {
+	return s.dir
+}
+
+// Size 返回已写入的字节总数
+func (s *BlobStore) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Put 将内容写入存储并返回句柄
+func (s *BlobStore) Put(content []byte) (*Blob, error) {
+	return s.PutReader(bytes.NewReader(content))
+}
+
+// PutReader 将 r 的全部内容流式写入存储并返回句柄，内容不会整体载入内存
+func (s *BlobStore) PutReader(r io.Reader) (*Blob, error) {
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		return nil, &DataContextError{Type: "blob_store_closed", Message: fmt.Sprintf("blob store %s is closed", s.dir)}
+	}
+
+	file, err := os.CreateTemp(s.dir, "blob-*")
+	if err != nil {
+		return nil, &DataContextError{Type: "blob_write_failed", Message: err.Error(), Cause: err}
+	}
+	size, err := io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return nil, &DataContextError{Type: "blob_write_failed", Key: filepath.Base(file.Name()), Message: err.Error(), Cause: err}
+	}
+
+	s.mu.Lock()
+	s.size += size
+	s.mu.Unlock()
+	return &Blob{ID: filepath.Base(file.Name()), Path: file.Name(), Size: size}, nil
+}
+
+// Close 删除存储目录及其中所有 blob，之后打开这些句柄会返回错误
+func (s *BlobStore) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+	return os.RemoveAll(s.dir)
+}
+
+// Blob 存储在 BlobStore 中的大值的句柄，DataContext 中保存句柄而不是内容
+// 句柄很小，快照与序列化时只输出 ID、路径与大小
+type Blob struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// Open 打开 blob 内容，调用方负责关闭
+func (b *Blob) Open() (io.ReadSeekCloser, error) {
+	file, err := os.Open(b.Path)
+	if err != nil {
+		errType := "blob_read_failed"
+		if errors.Is(err, fs.ErrNotExist) {
+			errType = "blob_unavailable"
+		}
+		return nil, &DataContextError{Type: errType, Key: b.ID, Message: err.Error(), Cause: err}
+	}
+	return file, nil
+}
+
+// Reader 返回在首次读取时打开、读到结尾或出错时自动关闭的 Reader，便于直接用于 io.Copy
+func (b *Blob) Reader() io.Reader {
+	return &blobReader{blob: b}
+}
+
+// Bytes 读取 blob 的全部内容
+func (b *Blob) Bytes() ([]byte, error) {
+	file, err := b.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+func (b *Blob) String() string {
+	return fmt.Sprintf("blob:%s (%d bytes)", b.ID, b.Size)
+}
+
+// blobReader 延迟打开 blob 的 Reader
+type blobReader struct {
+	blob *Blob
+	file io.ReadCloser
+	err  error
+}
+
+func (r *blobReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.file == nil {
+		file, err := r.blob.Open()
+		if err != nil {
+			r.err = err
+			return 0, err
+		}
+		r.file = file
+	}
+	n, err := r.file.Read(p)
+	if err != nil {
+		r.file.Close()
+		r.err = err
+	}
+	return n, err
+}
+
+// blobConfig 数据上下文关联的 blob 存储
+type blobConfig struct {
+	store     *BlobStore
+	threshold int
+}
+
+// AttachBlobStore 为 data 及其子作用域关联 blob 存储：threshold 大于 0 时，
+// 各写操作写入的超过 threshold 字节的 []byte 值会写入存储，键中保存 *Blob 句柄
+// 写入存储失败时仍保存原值。存储的生命周期由调用方管理（引擎见 WithBlobStorage）
+func AttachBlobStore(data DataContext, store *BlobStore, threshold int) error {
+	holder, ok := data.(interface {
+		attachBlobs(cfg *blobConfig) func()
+	})
+	if !ok {
+		return &DataContextError{Type: "blob_store_unsupported", Message: fmt.Sprintf("%T does not support blob storage", data)}
+	}
+	holder.attachBlobs(&blobConfig{store: store, threshold: threshold})
+	return nil
+}
+
+// BlobStoreFrom 返回与 data（或其父作用域）关联的 blob 存储
+func BlobStoreFrom(data DataContext) (*BlobStore, bool) {
+	holder, ok := data.(interface{ blobStorage() *blobConfig })
+	if !ok {
+		return nil, false
+	}
+	cfg := holder.blobStorage()
+	if cfg == nil {
+		return nil, false
+	}
+	return cfg.store, true
+}
+
+// SetBlob 将 r 的内容流式写入 data 关联的 blob 存储，并把句柄保存到 key
+func SetBlob(data DataContext, key string, r io.Reader) (*Blob, error) {
+	store, ok := BlobStoreFrom(data)
+	if !ok {
+		return nil, &DataContextError{Type: "no_blob_store", Key: key, Message: "no blob store is attached, see WithBlobStorage"}
+	}
+	blob, err := store.PutReader(r)
+	if err != nil {
+		return nil, err
+	}
+	data.Set(key, blob)
+	return blob, nil
+}
+
+// attachBlobs 关联 blob 存储并返回恢复之前设置的函数
+func (c *defaultDataContext) attachBlobs(cfg *blobConfig) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous := c.blobs
+	c.blobs = cfg
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.blobs = previous
+	}
+}
+
+// blobStorage 返回本作用域或最近的父作用域关联的 blob 存储
+func (c *defaultDataContext) blobStorage() *blobConfig {
+	c.mu.RLock()
+	cfg := c.blobs
+	c.mu.RUnlock()
+	if cfg != nil {
+		return cfg
+	}
+	return blobStorageOf(c.parent)
+}
+
+func blobStorageOf(data DataContext) *blobConfig {
+	if holder, ok := data.(interface{ blobStorage() *blobConfig }); ok {
+		return holder.blobStorage()
+	}
+	return nil
+}
+
+// exceeds 判断写入的值是否是超过阈值、需要写入 blob 存储的 []byte
+func (cfg *blobConfig) exceeds(value interface{}) bool {
+	content, ok := value.([]byte)
+	return ok && cfg != nil && cfg.threshold > 0 && len(content) > cfg.threshold
+}
+
+// spilledValue 已写入 blob 存储的内容及键中实际保存的值
+type spilledValue struct {
+	content []byte
+	stored  interface{}
+}
+
+// spill 将内容写入 blob 存储，写入失败时保存原值
+// 所有写操作（Set、原子操作与事务提交）经过这里，且在持有作用域的锁之外调用，见 modifyTTL
+func (cfg *blobConfig) spill(content []byte) *spilledValue {
+	spilled := &spilledValue{content: content, stored: content}
+	if blob, err := cfg.store.Put(content); err == nil {
+		spilled.stored = blob
+	}
+	return spilled
+}
+
+// matches 判断持锁时计算出的值是否与已写入存储的内容相同
+func (s *spilledValue) matches(value interface{}) bool {
+	content, _ := value.([]byte)
+	return s != nil && bytes.Equal(s.content, content)
+}
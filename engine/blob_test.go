@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestBlobStore(t *testing.T) {
+	store, err := NewBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	blob, err := store.Put([]byte("hello"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if blob.Size != 5 || store.Size() != 5 {
+		t.Errorf("Unexpected sizes: blob %d, store %d", blob.Size, store.Size())
+	}
+	if content, err := blob.Bytes(); err != nil || string(content) != "hello" {
+		t.Errorf("Expected blob content, got %q %v", content, err)
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, blob.Reader()); err != nil || buf.String() != "hello" {
+		t.Errorf("Expected Reader to stream content, got %q %v", buf.String(), err)
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(store.Dir()); !os.IsNotExist(err) {
+		t.Errorf("Expected store directory to be removed, got %v", err)
+	}
+	var dcErr *DataContextError
+	if _, err := blob.Open(); !errors.As(err, &dcErr) || dcErr.Type != "blob_unavailable" {
+		t.Errorf("Expected blob_unavailable after close, got %v", err)
+	}
+	if _, err := store.Put([]byte("x")); !errors.As(err, &dcErr) || dcErr.Type != "blob_store_closed" {
+		t.Errorf("Expected blob_store_closed, got %v", err)
+	}
+}
+
+func TestDataContextBlobSpill(t *testing.T) {
+	store, err := NewBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer store.Close()
+
+	data := NewDataContext()
+	if err := AttachBlobStore(data, store, 4); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	scope := data.Scope("s")
+	scope.Set("small", []byte("abc"))
+	scope.Set("large", []byte("abcdef"))
+
+	if v, _ := scope.Get("small"); !bytes.Equal(v.([]byte), []byte("abc")) {
+		t.Errorf("Expected small values to stay inline, got %v", v)
+	}
+	v, _ := scope.Get("large")
+	blob, ok := v.(*Blob)
+	if !ok || blob.Size != 6 {
+		t.Fatalf("Expected large value to be spilled, got %#v", v)
+	}
+	if content, ok := GetBytes(scope, "large"); !ok || string(content) != "abcdef" {
+		t.Errorf("Expected GetBytes to read the blob, got %q %v", content, ok)
+	}
+	if s, ok := Get[string](scope, "large"); !ok || s != "abcdef" {
+		t.Errorf("Expected Get[string] to read the blob, got %q %v", s, ok)
+	}
+
+	if _, err := SetBlob(scope, "streamed", strings.NewReader("streamed content")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if content, _ := GetBytes(scope, "streamed"); string(content) != "streamed content" {
+		t.Errorf("Unexpected streamed content %q", content)
+	}
+
+	// 原子操作与 Set 经过同一写入路径
+	scope.SetIfAbsent("absent", []byte("abcdef"))
+	if v, _ := scope.Get("absent"); !isBlob(v) {
+		t.Errorf("Expected SetIfAbsent to spill, got %#v", v)
+	}
+	if v := scope.Update("small", func(old interface{}, ok bool) interface{} {
+		return append(old.([]byte), "def"...)
+	}); !isBlob(v) {
+		t.Errorf("Expected Update to spill, got %#v", v)
+	}
+	if content, _ := GetBytes(scope, "small"); string(content) != "abcdef" {
+		t.Errorf("Unexpected updated content %q", content)
+	}
+
+	// 会被约束拒绝的值不写入存储
+	schemas := NewDataSchemaRegistry()
+	schemas.Register("text", &Schema{Type: SchemaType{"string"}})
+	if err := AttachDataSchemas(data, schemas, DataSchemaReject); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	size := store.Size()
+	scope.Set("text", []byte("abcdef"))
+	if scope.Has("text") || store.Size() != size {
+		t.Errorf("Expected the rejected value not to be stored, size %d -> %d", size, store.Size())
+	}
+
+	var dcErr *DataContextError
+	if _, err := SetBlob(NewDataContext(), "k", strings.NewReader("x")); !errors.As(err, &dcErr) || dcErr.Type != "no_blob_store" {
+		t.Errorf("Expected no_blob_store, got %v", err)
+	}
+}
+
+func TestEngineWithBlobStorage(t *testing.T) {
+	config := &Config{
+		Name: "blobs",
+		Layers: []LayerConfig{
+			{Name: "load", Mode: SerialMode, Components: []ComponentConfig{{Name: "reader", Type: "reader"}}},
+			{Name: "use", Mode: SerialMode, Components: []ComponentConfig{{Name: "user", Type: "user"}}},
+		},
+	}
+
+	var storeDir string
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "reader",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				store, ok := BlobStoreFrom(data)
+				if !ok {
+					t.Fatal("Expected a blob store during execution")
+				}
+				storeDir = store.Dir()
+				data.Set("file_data", bytes.Repeat([]byte("x"), 1024))
+				return nil
+			}}, nil
+		},
+	})
+	registry.Register(&MockComponentFactory{
+		componentType: "user",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				content, ok := GetBytes(data, "file_data")
+				if !ok || len(content) != 1024 {
+					t.Errorf("Expected to read the spilled value, got %d bytes", len(content))
+				}
+				return errors.New("fail after reading")
+			}}, nil
+		},
+	})
+
+	engine, err := NewEngine(config, registry, WithBlobStorage(t.TempDir(), 512), WithLogger(&MockLogger{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data := NewDataContext()
+	if _, err := engine.Execute(context.Background(), data); err == nil {
+		t.Fatal("Expected execution error")
+	}
+
+	if _, ok := data.Get("file_data"); !ok {
+		t.Fatal("Expected the blob handle to remain in the context")
+	}
+	if _, err := os.Stat(storeDir); !os.IsNotExist(err) {
+		t.Errorf("Expected blob storage to be removed after a failed run, got %v", err)
+	}
+	if _, ok := BlobStoreFrom(data); ok {
+		t.Error("Expected the blob store to be detached after the run")
+	}
+}
+
+func isBlob(v interface{}) bool {
+	_, ok := v.(*Blob)
+	return ok
+}
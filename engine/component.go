@@ -2,6 +2,10 @@ package engine
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
 	"time"
 )
 
@@ -62,13 +66,30 @@ type ComponentConfig struct {
     Type         string                 `json:"type"`
     Config       map[string]interface{} `json:"config"`
     Dependencies []string               `json:"dependencies"`
+    Inputs       []string               `json:"inputs,omitempty"`  // 读取的 DataContext 键，用于数据流校验
+    Outputs      []string               `json:"outputs,omitempty"` // 写入的 DataContext 键，用于数据流校验
     Timeout      time.Duration          `json:"timeout"`
     Retry        *RetryConfig           `json:"retry,omitempty"`
-    Critical     bool                   `json:"critical"`
-    Enabled      bool                   `json:"enabled"`
+    Critical     *bool                  `json:"critical,omitempty"` // nil 表示未设置，默认 false
+    Enabled      *bool                  `json:"enabled,omitempty"`  // nil 表示未设置，默认 true
     Remove       bool                   `json:"remove,omitempty"`
 }
 
+// IsEnabled 返回组件是否启用，未设置时默认启用
+func (c ComponentConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// IsCritical 返回组件是否为关键组件，未设置时默认为非关键
+func (c ComponentConfig) IsCritical() bool {
+	return c.Critical != nil && *c.Critical
+}
+
+// BoolPtr 返回指向给定布尔值的指针，便于在代码中构造 enabled/critical 等三态字段
+func BoolPtr(v bool) *bool {
+	return &v
+}
+
 // ComponentFactory 组件工厂接口
 type ComponentFactory interface {
 	// Create 根据配置创建组件实例
@@ -78,9 +99,18 @@ type ComponentFactory interface {
 	GetType() string
 }
 
-// ComponentRegistry 组件注册表
+// SchemaProvider 组件工厂可选实现的接口，用于为组件的 config 字段提供 JSON Schema
+// 提供后可通过 GenerateConfigSchema 导出，并在解析配置时（WithSchemaValidation）校验组件配置
+type SchemaProvider interface {
+	// ConfigSchema 返回组件 config 字段的 Schema
+	ConfigSchema() *Schema
+}
+
+// ComponentRegistry 组件注册表，可以并发注册与查询
 type ComponentRegistry struct {
+	mu        sync.RWMutex
 	factories map[string]ComponentFactory
+	secrets   SecretProvider
 }
 
 // NewComponentRegistry 创建新的组件注册表
@@ -90,14 +120,110 @@ func NewComponentRegistry() *ComponentRegistry {
 	}
 }
 
-// Register 注册组件工厂
-func (r *ComponentRegistry) Register(factory ComponentFactory) {
+// DefaultComponents 默认的组件注册表，组件包可以在 init 中通过 RegisterComponent 注册自己的工厂
+var DefaultComponents = NewComponentRegistry()
+
+// RegisterComponent 在 DefaultComponents 中注册组件工厂，通常在 init 中调用
+func RegisterComponent(factory ComponentFactory) error {
+	return DefaultComponents.Register(factory)
+}
+
+// MustRegisterComponent 与 RegisterComponent 相同，注册失败时 panic，便于在 init 中使用
+func MustRegisterComponent(factory ComponentFactory) {
+	if err := RegisterComponent(factory); err != nil {
+		panic(err)
+	}
+}
+
+// Register 注册组件工厂，同一类型已注册时返回 ComponentError（duplicate_factory），需要覆盖时使用 Replace
+func (r *ComponentRegistry) Register(factory ComponentFactory) error {
+	if err := validateFactory(factory); err != nil {
+		return err
+	}
+	componentType := factory.GetType()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.factories[componentType]; ok {
+		return &ComponentError{
+			Type:    "duplicate_factory",
+			Message: fmt.Sprintf("component type %s is already registered by %T", componentType, existing),
+		}
+	}
+	r.factories[componentType] = factory
+	return nil
+}
+
+// Replace 注册组件工厂并覆盖同一类型已注册的工厂，返回被覆盖的工厂（没有时为 nil）
+func (r *ComponentRegistry) Replace(factory ComponentFactory) (ComponentFactory, error) {
+	if err := validateFactory(factory); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous := r.factories[factory.GetType()]
 	r.factories[factory.GetType()] = factory
+	return previous, nil
 }
 
-// Create 根据配置创建组件
+// Unregister 移除组件类型的工厂，类型未注册时返回 false
+func (r *ComponentRegistry) Unregister(componentType string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.factories[componentType]; !ok {
+		return false
+	}
+	delete(r.factories, componentType)
+	return true
+}
+
+// Factory 返回组件类型的工厂
+func (r *ComponentRegistry) Factory(componentType string) (ComponentFactory, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	factory, ok := r.factories[componentType]
+	return factory, ok
+}
+
+// validateFactory 检查工厂非空且声明了类型
+// 包装在接口中的 nil 指针（如 (*MyFactory)(nil)）同样视为空，GetType panic 时返回错误而不是传播 panic
+func validateFactory(factory ComponentFactory) (err error) {
+	if factory == nil {
+		return &ComponentError{Type: "invalid_factory", Message: "component factory cannot be nil"}
+	}
+	switch rv := reflect.ValueOf(factory); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		if rv.IsNil() {
+			return &ComponentError{Type: "invalid_factory", Message: fmt.Sprintf("component factory %T is nil", factory)}
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = &ComponentError{Type: "invalid_factory", Message: fmt.Sprintf("component factory %T panicked in GetType: %v", factory, r)}
+		}
+	}()
+	if factory.GetType() == "" {
+		return &ComponentError{Type: "invalid_factory", Message: fmt.Sprintf("component factory %T has an empty type", factory)}
+	}
+	return nil
+}
+
+// SetSecretProvider 设置密钥提供者，组件配置中的 secret:// 引用会在 Create 时解析为 Secret
+// 解析后这些字段不再是 string，工厂应使用 ConfigString 或 DecodeConfig 读取
+func (r *ComponentRegistry) SetSecretProvider(provider SecretProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.secrets = provider
+}
+
+// Create 根据配置创建组件，传给工厂的配置中 secret:// 引用已解析为 Secret（见 SetSecretProvider）
 func (r *ComponentRegistry) Create(config ComponentConfig) (Component, error) {
+	r.mu.RLock()
 	factory, exists := r.factories[config.Type]
+	secrets := r.secrets
+	r.mu.RUnlock()
 	if !exists {
 		return nil, &ComponentError{
 			Type:    "factory_not_found",
@@ -105,14 +231,37 @@ func (r *ComponentRegistry) Create(config ComponentConfig) (Component, error) {
 		}
 	}
 
-	return factory.Create(config)
+	// 仅在创建组件时解析密钥，传给工厂的是配置副本
+	resolved, err := resolveSecrets(context.Background(), secrets, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return factory.Create(resolved)
 }
 
-// GetRegisteredTypes 获取所有已注册的组件类型
+// GetRegisteredTypes 获取所有已注册的组件类型，按名称排序
 func (r *ComponentRegistry) GetRegisteredTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	types := make([]string, 0, len(r.factories))
 	for t := range r.factories {
 		types = append(types, t)
 	}
+	sort.Strings(types)
 	return types
 }
+
+// GetSchema 获取指定组件类型的 config Schema，工厂未实现 SchemaProvider 时返回 false
+func (r *ComponentRegistry) GetSchema(componentType string) (*Schema, bool) {
+	factory, exists := r.Factory(componentType)
+	if !exists {
+		return nil, false
+	}
+	provider, ok := factory.(SchemaProvider)
+	if !ok {
+		return nil, false
+	}
+	schema := provider.ConfigSchema()
+	return schema, schema != nil
+}
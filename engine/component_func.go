@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"context"
+)
+
+// ComponentFunc 将函数包装为组件，适用于不需要配置与状态的小步骤
+func ComponentFunc(name string, fn func(ctx context.Context, data DataContext) error) Component {
+	return &funcComponent{name: name, fn: fn}
+}
+
+type funcComponent struct {
+	name string
+	fn   func(ctx context.Context, data DataContext) error
+}
+
+func (c *funcComponent) Name() string {
+	return c.name
+}
+
+func (c *funcComponent) Execute(ctx context.Context, data DataContext) error {
+	return c.fn(ctx, data)
+}
+
+// FactoryFunc 将函数包装为组件工厂，省去单独的工厂类型
+func FactoryFunc(componentType string, create func(config ComponentConfig) (Component, error)) ComponentFactory {
+	return &funcFactory{componentType: componentType, create: create}
+}
+
+type funcFactory struct {
+	componentType string
+	create        func(config ComponentConfig) (Component, error)
+}
+
+func (f *funcFactory) Create(config ComponentConfig) (Component, error) {
+	return f.create(config)
+}
+
+func (f *funcFactory) GetType() string {
+	return f.componentType
+}
+
+// ComponentStruct 可由 RegisterType 创建的组件结构体：*T 实现 Execute
+type ComponentStruct[T any] interface {
+	*T
+	Execute(ctx context.Context, data DataContext) error
+}
+
+// RegisterType 以 componentType 注册由结构体 T 构建组件的工厂：每次创建时按 DecodeConfig 的规则
+// 将 ComponentConfig.Config 解码到新的 T（支持 config/default 标签）。*T 实现 Validate() error 时，
+// 创建时不调用，组件作为 ValidatableComponent 由 Layer.Validate 调用一次
+//
+//	type upper struct {
+//	    Key string `config:"key,required"`
+//	}
+//	func (u *upper) Execute(ctx context.Context, data engine.DataContext) error { ... }
+//
+//	engine.RegisterType[upper](registry, "upper")
+//
+// *T 未实现 Name 时组件名称取自配置，此时组件只保留 Initialize、Cleanup 与 Validate；
+// 需要重试或数据流声明（RetryableComponent、DataFlowComponent）时为 *T 实现 Name，*T 将直接作为组件使用
+func RegisterType[T any, P ComponentStruct[T]](registry *ComponentRegistry, componentType string) error {
+	return registry.Register(FactoryFunc(componentType, func(config ComponentConfig) (Component, error) {
+		impl := P(new(T))
+		if err := decodeConfigMap(config.Config, impl); err != nil {
+			return nil, err
+		}
+		if component, ok := interface{}(impl).(Component); ok {
+			return component, nil
+		}
+		return &structComponent{name: config.Name, impl: impl}, nil
+	}))
+}
+
+// structComponent 为未实现 Name 的结构体提供配置中的名称
+type structComponent struct {
+	name string
+	impl interface {
+		Execute(ctx context.Context, data DataContext) error
+	}
+}
+
+func (c *structComponent) Name() string {
+	return c.name
+}
+
+func (c *structComponent) Execute(ctx context.Context, data DataContext) error {
+	return c.impl.Execute(ctx, data)
+}
+
+func (c *structComponent) Initialize(ctx context.Context) error {
+	if initializer, ok := c.impl.(interface {
+		Initialize(ctx context.Context) error
+	}); ok {
+		return initializer.Initialize(ctx)
+	}
+	return nil
+}
+
+func (c *structComponent) Cleanup(ctx context.Context) error {
+	if cleaner, ok := c.impl.(interface {
+		Cleanup(ctx context.Context) error
+	}); ok {
+		return cleaner.Cleanup(ctx)
+	}
+	return nil
+}
+
+func (c *structComponent) Validate() error {
+	if validatable, ok := c.impl.(interface{ Validate() error }); ok {
+		return validatable.Validate()
+	}
+	return nil
+}
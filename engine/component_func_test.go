@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// upperComponent 由 RegisterType 构建的组件，没有 Name 方法
+type upperComponent struct {
+	Key    string `config:"key,required"`
+	Suffix string `config:"suffix" default:"!"`
+}
+
+func (u *upperComponent) Execute(ctx context.Context, data DataContext) error {
+	value, _ := data.GetString(u.Key)
+	data.Set(u.Key, strings.ToUpper(value)+u.Suffix)
+	return nil
+}
+
+// namedComponent 实现了 Name 的结构体直接作为组件使用
+type namedComponent struct {
+	Retries int `config:"retries"`
+}
+
+func (n *namedComponent) Name() string { return "named" }
+
+func (n *namedComponent) Execute(ctx context.Context, data DataContext) error { return nil }
+
+func (n *namedComponent) ShouldRetry(err error) bool { return true }
+
+func (n *namedComponent) GetRetryConfig() RetryConfig { return RetryConfig{MaxRetries: n.Retries} }
+
+// validatedComponent 统计 Validate 的调用次数，计数器由测试在创建后注入
+type validatedComponent struct {
+	Limit int `config:"limit"`
+	calls *int
+}
+
+func (v *validatedComponent) Execute(ctx context.Context, data DataContext) error { return nil }
+
+func (v *validatedComponent) Validate() error {
+	// 创建期间调用 Validate 时计数器尚未注入
+	if v.calls == nil {
+		return errors.New("validated during creation")
+	}
+	*v.calls++
+	if v.Limit < 0 {
+		return errors.New("limit must not be negative")
+	}
+	return nil
+}
+
+func TestComponentFunc(t *testing.T) {
+	registry := NewComponentRegistry()
+	err := registry.Register(FactoryFunc("greet", func(config ComponentConfig) (Component, error) {
+		greeting, _ := config.Config["greeting"].(string)
+		return ComponentFunc(config.Name, func(ctx context.Context, data DataContext) error {
+			data.Set("greeting", greeting)
+			return nil
+		}), nil
+	}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	component, err := registry.Create(ComponentConfig{Name: "hello", Type: "greet", Config: map[string]interface{}{"greeting": "hi"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if component.Name() != "hello" {
+		t.Errorf("Expected name from config, got %s", component.Name())
+	}
+	data := NewDataContext()
+	if err := component.Execute(context.Background(), data); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if v, _ := data.GetString("greeting"); v != "hi" {
+		t.Errorf("Expected greeting to be set, got %q", v)
+	}
+}
+
+func TestRegisterType(t *testing.T) {
+	registry := NewComponentRegistry()
+	if err := RegisterType[upperComponent](registry, "upper"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := RegisterType[upperComponent](registry, "upper"); err == nil {
+		t.Error("Expected duplicate registration to fail")
+	}
+
+	component, err := registry.Create(ComponentConfig{Name: "shout", Type: "upper", Config: map[string]interface{}{"key": "text"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if component.Name() != "shout" {
+		t.Errorf("Expected name from config, got %s", component.Name())
+	}
+	data := NewDataContextWith(map[string]interface{}{"text": "hello"})
+	if err := component.Execute(context.Background(), data); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if v, _ := data.GetString("text"); v != "HELLO!" {
+		t.Errorf("Expected decoded config with defaults, got %q", v)
+	}
+
+	var verrs ValidationErrors
+	if _, err := registry.Create(ComponentConfig{Name: "bad", Type: "upper"}); !errors.As(err, &verrs) || verrs[0].Field != "config.key" {
+		t.Errorf("Expected ValidationErrors for the missing key, got %v", err)
+	}
+}
+
+func TestRegisterTypeWithName(t *testing.T) {
+	registry := NewComponentRegistry()
+	RegisterType[namedComponent](registry, "named")
+
+	component, err := registry.Create(ComponentConfig{Name: "ignored", Type: "named", Config: map[string]interface{}{"retries": 2}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	retryable, ok := component.(RetryableComponent)
+	if !ok || retryable.GetRetryConfig().MaxRetries != 2 {
+		t.Errorf("Expected the struct to be used as the component, got %T", component)
+	}
+}
+
+func TestRegisterTypeValidatesOnce(t *testing.T) {
+	types := NewComponentRegistry()
+	if err := RegisterType[validatedComponent](types, "validated"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// 包装 RegisterType 的工厂，为每个组件注入本测试的计数器
+	calls := 0
+	registry := NewComponentRegistry()
+	err := registry.Register(FactoryFunc("validated", func(config ComponentConfig) (Component, error) {
+		component, err := types.Create(config)
+		if err != nil {
+			return nil, err
+		}
+		component.(*structComponent).impl.(*validatedComponent).calls = &calls
+		return component, nil
+	}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	config := &Config{
+		Name:   "validated",
+		Layers: []LayerConfig{{Name: "l", Mode: SerialMode, Components: []ComponentConfig{{Name: "v", Type: "validated", Config: map[string]interface{}{"limit": 1}}}}},
+	}
+
+	if _, err := NewEngine(config, registry, WithLogger(&MockLogger{})); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected Validate to run once, got %d", calls)
+	}
+
+	config.Layers[0].Components[0].Config["limit"] = -1
+	if _, err := NewEngine(config, registry, WithLogger(&MockLogger{})); err == nil || !strings.Contains(err.Error(), "limit must not be negative") {
+		t.Errorf("Expected the validation error, got %v", err)
+	}
+}
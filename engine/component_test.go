@@ -3,6 +3,9 @@ package engine
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
 )
@@ -133,8 +136,8 @@ func TestComponentConfig(t *testing.T) {
 			Dependencies: []string{"dep1", "dep2"},
 			Timeout:      30 * time.Second,
 			Retry:        retryConfig,
-			Critical:     true,
-			Enabled:      true,
+			Critical:     BoolPtr(true),
+			Enabled:      BoolPtr(true),
 		}
 
 		if config.Name != "test-component" {
@@ -146,10 +149,10 @@ func TestComponentConfig(t *testing.T) {
 		if len(config.Dependencies) != 2 {
 			t.Errorf("Expected 2 dependencies, got %d", len(config.Dependencies))
 		}
-		if !config.Critical {
+		if !config.IsCritical() {
 			t.Error("Expected Critical to be true")
 		}
-		if !config.Enabled {
+		if !config.IsEnabled() {
 			t.Error("Expected Enabled to be true")
 		}
 	})
@@ -264,18 +267,106 @@ func TestComponentRegistry(t *testing.T) {
 			t.Errorf("Expected 2 registered types, got %d", len(types))
 		}
 
-		// Check that both types are present (order may vary)
-		typeMap := make(map[string]bool)
-		for _, t := range types {
-			typeMap[t] = true
+		// Types are sorted by name
+		if !reflect.DeepEqual(types, []string{"type1", "type2"}) {
+			t.Errorf("Expected sorted types [type1 type2], got %v", types)
+		}
+	})
+
+	t.Run("Register duplicate type", func(t *testing.T) {
+		registry := NewComponentRegistry()
+		first := &MockComponentFactory{componentType: "test-type"}
+		second := &MockComponentFactory{componentType: "test-type"}
+
+		if err := registry.Register(first); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		var compErr *ComponentError
+		if err := registry.Register(second); !errors.As(err, &compErr) || compErr.Type != "duplicate_factory" {
+			t.Errorf("Expected duplicate_factory error, got %v", err)
+		}
+		if factory, _ := registry.Factory("test-type"); factory != first {
+			t.Error("Expected the duplicate registration not to overwrite the factory")
 		}
 
-		if !typeMap["type1"] {
-			t.Error("Expected 'type1' to be registered")
+		previous, err := registry.Replace(second)
+		if err != nil || previous != first {
+			t.Errorf("Expected Replace to return the previous factory, got %v %v", previous, err)
+		}
+		if factory, _ := registry.Factory("test-type"); factory != second {
+			t.Error("Expected Replace to overwrite the factory")
+		}
+	})
+
+	t.Run("Register invalid factory", func(t *testing.T) {
+		registry := NewComponentRegistry()
+		var compErr *ComponentError
+		if err := registry.Register(&MockComponentFactory{}); !errors.As(err, &compErr) || compErr.Type != "invalid_factory" {
+			t.Errorf("Expected invalid_factory error, got %v", err)
+		}
+		if err := registry.Register(nil); !errors.As(err, &compErr) || compErr.Type != "invalid_factory" {
+			t.Errorf("Expected invalid_factory error, got %v", err)
+		}
+		// 包装在接口中的 nil 指针，GetType 会解引用 nil
+		var typedNil *MockComponentFactory
+		if err := registry.Register(typedNil); !errors.As(err, &compErr) || compErr.Type != "invalid_factory" {
+			t.Errorf("Expected invalid_factory error for a typed nil factory, got %v", err)
+		}
+		if _, err := registry.Replace(typedNil); !errors.As(err, &compErr) || compErr.Type != "invalid_factory" {
+			t.Errorf("Expected invalid_factory error for a typed nil factory, got %v", err)
 		}
-		if !typeMap["type2"] {
-			t.Error("Expected 'type2' to be registered")
+	})
+
+	t.Run("Unregister", func(t *testing.T) {
+		registry := NewComponentRegistry()
+		registry.Register(&MockComponentFactory{componentType: "test-type"})
+
+		if !registry.Unregister("test-type") {
+			t.Error("Expected Unregister to report the removed type")
+		}
+		if registry.Unregister("test-type") {
+			t.Error("Expected Unregister of a missing type to return false")
+		}
+		if _, err := registry.Create(ComponentConfig{Name: "c", Type: "test-type"}); err == nil {
+			t.Error("Expected error creating an unregistered type")
+		}
+	})
+
+	t.Run("Concurrent registration and lookup", func(t *testing.T) {
+		registry := NewComponentRegistry()
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				registry.Register(&MockComponentFactory{componentType: fmt.Sprintf("type%02d", i)})
+			}(i)
+			go func() {
+				defer wg.Done()
+				registry.GetRegisteredTypes()
+				registry.Create(ComponentConfig{Name: "c", Type: "type00"})
+			}()
+		}
+		wg.Wait()
+		if types := registry.GetRegisteredTypes(); len(types) != 20 || types[0] != "type00" {
+			t.Errorf("Expected 20 sorted types, got %v", types)
+		}
+	})
+
+	t.Run("Default registry", func(t *testing.T) {
+		factory := &MockComponentFactory{componentType: "default-test-type"}
+		defer DefaultComponents.Unregister("default-test-type")
+
+		MustRegisterComponent(factory)
+		if got, ok := DefaultComponents.Factory("default-test-type"); !ok || got != factory {
+			t.Error("Expected the factory in the default registry")
 		}
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected MustRegisterComponent to panic on a duplicate")
+			}
+		}()
+		MustRegisterComponent(factory)
 	})
 }
 
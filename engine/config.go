@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
-	"regexp"
+	"path"
+	"path/filepath"
 	"strings"
 	"time"
 )
@@ -19,37 +21,101 @@ type Config struct {
     Global      map[string]interface{} `json:"global,omitempty"`
     Timeout     time.Duration          `json:"timeout,omitempty"`
     Metadata    map[string]string      `json:"metadata,omitempty"`
-    Extends     string                 `json:"extends,omitempty"`
+    Extends     ExtendsList            `json:"extends,omitempty"`
+    Profiles    map[string]ProfileConfig `json:"profiles,omitempty"`
+    Inputs      []InputConfig          `json:"inputs,omitempty"`
+}
+
+// ExtendsList 继承的父配置路径列表，按顺序合并（后者覆盖前者）
+// JSON 中既可以是单个字符串，也可以是字符串数组
+type ExtendsList []string
+
+// MarshalJSON 单个父配置输出为字符串，多个输出为数组
+func (e ExtendsList) MarshalJSON() ([]byte, error) {
+    if len(e) == 1 {
+        return json.Marshal(e[0])
+    }
+    return json.Marshal([]string(e))
+}
+
+// UnmarshalJSON 接受字符串或字符串数组
+func (e *ExtendsList) UnmarshalJSON(data []byte) error {
+    var single string
+    if err := json.Unmarshal(data, &single); err == nil {
+        if single == "" {
+            *e = nil
+        } else {
+            *e = ExtendsList{single}
+        }
+        return nil
+    }
+    var list []string
+    if err := json.Unmarshal(data, &list); err != nil {
+        return fmt.Errorf("extends must be a string or an array of strings: %w", err)
+    }
+    *e = ExtendsList(list)
+    return nil
 }
 
 
 // ConfigParser 配置解析器
 type ConfigParser struct {
-    envVarPattern *regexp.Regexp
-    visitedExtends map[string]bool
+    variables     VariableSource
+    strict        bool
+    schemas       *ComponentRegistry
+    profile       string
+}
+
+// ParserOption 配置解析器选项
+type ParserOption func(*ConfigParser)
+
+// WithStrictMode 启用严格模式：配置中出现未定义字段（包括废弃字段）时返回 ConfigError
+func WithStrictMode() ParserOption {
+    return func(p *ConfigParser) {
+        p.strict = true
+    }
+}
+
+// WithSchemaValidation 使用注册表中组件工厂提供的 Schema（见 SchemaProvider）校验组件的 config 字段
+func WithSchemaValidation(registry *ComponentRegistry) ParserOption {
+    return func(p *ConfigParser) {
+        p.schemas = registry
+    }
 }
 
 // NewConfigParser 创建新的配置解析器
-func NewConfigParser() *ConfigParser {
-    return &ConfigParser{
-        envVarPattern: regexp.MustCompile(`\$\{([^}]+)\}`),
-        visitedExtends: make(map[string]bool),
+func NewConfigParser(options ...ParserOption) *ConfigParser {
+    parser := &ConfigParser{
+        variables: EnvSource(),
     }
+    for _, option := range options {
+        option(parser)
+    }
+    return parser
 }
 
-// ParseFile 从文件解析配置
+// ParseFile 从文件解析配置，extends 中的相对路径相对于该文件所在目录解析
 func (p *ConfigParser) ParseFile(filename string) (*Config, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, &ConfigError{
-			Type:    "file_open_failed",
-			Message: fmt.Sprintf("failed to open config file: %v", err),
-			Cause:   err,
-		}
-	}
-	defer file.Close()
+    config, err := p.loadFile(newParseState(nil), filename)
+    if err != nil {
+        return nil, err
+    }
+    if config, err = p.selectProfile(config); err != nil {
+        return nil, err
+    }
+    return p.finalize(config)
+}
 
-	return p.Parse(file)
+// ParseFS 从 fs.FS（如 embed.FS）中解析配置，extends 在同一文件系统内相对于包含文件解析
+func (p *ConfigParser) ParseFS(fsys fs.FS, name string) (*Config, error) {
+    config, err := p.loadFile(newParseState(fsys), name)
+    if err != nil {
+        return nil, err
+    }
+    if config, err = p.selectProfile(config); err != nil {
+        return nil, err
+    }
+    return p.finalize(config)
 }
 
 // Parse 从 Reader 解析配置
@@ -66,13 +132,125 @@ func (p *ConfigParser) Parse(reader io.Reader) (*Config, error) {
 	return p.ParseBytes(data)
 }
 
-// ParseBytes 从字节数组解析配置
+// ParseBytes 从字节数组解析配置，extends 中的相对路径相对于当前工作目录解析
 func (p *ConfigParser) ParseBytes(data []byte) (*Config, error) {
-    // 替换环境变量
-    configStr := p.replaceEnvVars(string(data))
+    config, err := p.loadBytes(newParseState(nil), data, "")
+    if err != nil {
+        return nil, err
+    }
+    if config, err = p.selectProfile(config); err != nil {
+        return nil, err
+    }
+    return p.finalize(config)
+}
+
+// parseState 单次解析的状态，用于在 extends 链与 $include 片段上检测循环引用
+// 每次 Parse* 调用都会创建新的状态，因此同一个 ConfigParser 可以安全地重复使用
+type parseState struct {
+    fsys     fs.FS
+    visiting map[string]bool
+}
+
+func newParseState(fsys fs.FS) *parseState {
+    return &parseState{fsys: fsys, visiting: make(map[string]bool)}
+}
+
+// resolve 解析 extends/$include 引用的路径，相对路径基于包含文件所在目录
+func (s *parseState) resolve(dir, ref string) string {
+    if s.fsys != nil {
+        if dir == "" || path.IsAbs(ref) {
+            return path.Clean(strings.TrimPrefix(ref, "/"))
+        }
+        return path.Join(dir, ref)
+    }
+    if dir == "" || filepath.IsAbs(ref) {
+        return ref
+    }
+    return filepath.Join(dir, ref)
+}
+
+// key 返回文件的唯一标识，用于循环检测
+func (s *parseState) key(name string) string {
+    if s.fsys != nil {
+        return path.Clean(name)
+    }
+    if abs, err := filepath.Abs(name); err == nil {
+        return abs
+    }
+    return filepath.Clean(name)
+}
+
+// dir 返回文件所在目录
+func (s *parseState) dir(name string) string {
+    if s.fsys != nil {
+        return path.Dir(name)
+    }
+    return filepath.Dir(name)
+}
+
+// readFile 读取配置文件
+func (s *parseState) readFile(name string) ([]byte, error) {
+    if s.fsys != nil {
+        return fs.ReadFile(s.fsys, name)
+    }
+    return os.ReadFile(name)
+}
+
+// loadFile 读取并加载配置文件（包含 extends 合并），不做验证与默认值填充
+func (p *ConfigParser) loadFile(state *parseState, name string) (*Config, error) {
+    key := state.key(name)
+    if state.visiting[key] {
+        return nil, &ConfigError{
+            Type:    "extends_cycle_detected",
+            Message: fmt.Sprintf("circular extends detected: %s", name),
+        }
+    }
+    state.visiting[key] = true
+    defer delete(state.visiting, key)
+
+    data, err := state.readFile(name)
+    if err != nil {
+        return nil, &ConfigError{
+            Type:    "file_open_failed",
+            Message: fmt.Sprintf("failed to open config file: %v", err),
+            Cause:   err,
+        }
+    }
+
+    return p.loadBytes(state, data, state.dir(name))
+}
+
+// loadBytes 解析配置内容并按顺序合并 extends 中的父配置，不做验证与默认值填充
+func (p *ConfigParser) loadBytes(state *parseState, data []byte, dir string) (*Config, error) {
+    // 对解析后的字符串值进行变量插值
+    raw, err := p.interpolateJSON(data)
+    if err != nil {
+        return nil, err
+    }
+
+    // 展开 layers/components 中的 $include 片段
+    if err := p.expandIncludes(state, raw, dir); err != nil {
+        return nil, err
+    }
+
+    // 严格模式：拒绝未知字段
+    if p.strict {
+        if err := p.checkUnknownFields(raw); err != nil {
+            return nil, err
+        }
+    }
+
+    interpolated, err := json.Marshal(raw)
+    if err != nil {
+        return nil, &ConfigError{
+            Type:    "json_marshal_failed",
+            Message: fmt.Sprintf("failed to marshal interpolated config: %v", err),
+            Cause:   err,
+        }
+    }
 
     var config Config
-    if err := json.Unmarshal([]byte(configStr), &config); err != nil {
+    if err := json.Unmarshal(interpolated, &config); err != nil {
         return nil, &ConfigError{
             Type:    "json_unmarshal_failed",
             Message: fmt.Sprintf("failed to unmarshal JSON config: %v", err),
@@ -80,50 +258,85 @@ func (p *ConfigParser) ParseBytes(data []byte) (*Config, error) {
         }
     }
 
-    // 继承支持：如果存在 extends，则加载父配置并合并
-    if config.Extends != "" {
-        if p.visitedExtends[config.Extends] {
-            return nil, &ConfigError{
-                Type:    "extends_cycle_detected",
-                Message: fmt.Sprintf("circular extends detected: %s", config.Extends),
-            }
-        }
-        p.visitedExtends[config.Extends] = true
+    if len(config.Extends) == 0 {
+        return &config, nil
+    }
 
-        parent, err := p.ParseFile(config.Extends)
+    // 继承支持：按顺序加载父配置，后面的父配置覆盖前面的，最后合并子配置
+    var base *Config
+    for _, ref := range config.Extends {
+        parent, err := p.loadFile(state, state.resolve(dir, ref))
         if err != nil {
             return nil, err
         }
-
-        merged, err := p.mergeConfigs(parent, &config)
-        if err != nil {
-            return nil, err
+        if base == nil {
+            base = parent
+            continue
         }
-
-        // 验证合并后的配置
-        if err := p.validateConfig(merged); err != nil {
+        if base, err = p.mergeConfigs(base, parent); err != nil {
             return nil, err
         }
-        // 设置默认值
-        p.setDefaults(merged)
-        return merged, nil
     }
 
+    merged, err := p.mergeConfigs(base, &config)
+    if err != nil {
+        return nil, err
+    }
+    // 合并结果已完整展开，不再保留 extends
+    merged.Extends = nil
+    return merged, nil
+}
+
+// finalize 验证合并后的配置并填充默认值
+func (p *ConfigParser) finalize(config *Config) (*Config, error) {
     // 验证配置
-    if err := p.validateConfig(&config); err != nil {
+    if err := p.validateConfig(config); err != nil {
         return nil, err
     }
 
     // 设置默认值
-    p.setDefaults(&config)
+    p.setDefaults(config)
+
+    // 按组件 Schema 校验组件配置
+    if err := p.validateComponentSchemas(config); err != nil {
+        return nil, err
+    }
 
-    return &config, nil
+    return config, nil
+}
+
+// validateComponentSchemas 使用注册的组件 Schema 校验各组件的 config 字段
+func (p *ConfigParser) validateComponentSchemas(config *Config) error {
+    if p.schemas == nil {
+        return nil
+    }
+
+    var errs ValidationErrors
+    for i, layer := range config.Layers {
+        for j, component := range layer.Components {
+            schema, ok := p.schemas.GetSchema(component.Type)
+            if !ok {
+                continue
+            }
+            var value interface{} = component.Config
+            if component.Config == nil {
+                value = map[string]interface{}{}
+            }
+            errs = append(errs, schema.Validate(value, fmt.Sprintf("layers[%d].components[%d].config", i, j))...)
+        }
+    }
+
+    // 报告所有组件的全部错误，一次列出所有拼写错误
+    if len(errs) > 0 {
+        return errs
+    }
+    return nil
 }
 
 // mergeConfigs 合并父子配置，实现继承与增删改
 func (p *ConfigParser) mergeConfigs(parent, child *Config) (*Config, error) {
-    // 基于父配置克隆副本
-    base, err := parent.Clone()
+    // 基于父配置深拷贝副本（父配置尚未验证，不能使用 Clone）
+    base, err := parent.deepCopy()
     if err != nil {
         return nil, err
     }
@@ -155,6 +368,15 @@ func (p *ConfigParser) mergeConfigs(parent, child *Config) (*Config, error) {
             base.Metadata[k] = v
         }
     }
+    // 输入声明按名称合并，同名输入由子配置整体覆盖
+    base.Inputs = mergeInputs(base.Inputs, child.Inputs)
+    // 同名 profile 由子配置整体覆盖
+    if child.Profiles != nil {
+        if base.Profiles == nil { base.Profiles = make(map[string]ProfileConfig) }
+        for k, v := range child.Profiles {
+            base.Profiles[k] = v
+        }
+    }
 
     // 构建父层索引
     layerIdx := make(map[string]int)
@@ -183,10 +405,8 @@ func (p *ConfigParser) mergeConfigs(parent, child *Config) (*Config, error) {
             bl := base.Layers[idx]
             if cl.Mode != "" { bl.Mode = cl.Mode }
             if cl.Timeout > 0 { bl.Timeout = cl.Timeout }
-            // Enabled：仅在子为 true 时覆盖，避免未显式提供导致覆盖为 false
-            if cl.Enabled {
-                bl.Enabled = true
-            }
+            // Enabled：子配置显式设置（true/false）时覆盖，未设置则保留父配置；复制值而不是共享指针
+            if cl.Enabled != nil { bl.Enabled = BoolPtr(*cl.Enabled) }
             if cl.Parallel > 0 { bl.Parallel = cl.Parallel }
             if len(cl.Dependencies) > 0 { bl.Dependencies = cl.Dependencies }
 
@@ -207,15 +427,12 @@ func (p *ConfigParser) mergeConfigs(parent, child *Config) (*Config, error) {
                     bc := bl.Components[cidx]
                     if cc.Type != "" { bc.Type = cc.Type }
                     if cc.Timeout > 0 { bc.Timeout = cc.Timeout }
-                    // 仅在子为 true 时覆盖 enabled，避免未显式提供导致覆盖为 false
-                    if cc.Enabled {
-                        bc.Enabled = true
-                    }
-                    // 仅在子为 true 时覆盖 critical（如需关闭可通过 remove 删除）
-                    if cc.Critical {
-                        bc.Critical = true
-                    }
+                    // enabled/critical：子配置显式设置（true/false）时覆盖，未设置则保留父配置；复制值而不是共享指针
+                    if cc.Enabled != nil { bc.Enabled = BoolPtr(*cc.Enabled) }
+                    if cc.Critical != nil { bc.Critical = BoolPtr(*cc.Critical) }
                     if len(cc.Dependencies) > 0 { bc.Dependencies = cc.Dependencies }
+                    if cc.Inputs != nil { bc.Inputs = cc.Inputs }
+                    if cc.Outputs != nil { bc.Outputs = cc.Outputs }
                     // 合并 config（子覆盖父）
                     if cc.Config != nil {
                         if bc.Config == nil { bc.Config = make(map[string]interface{}) }
@@ -240,30 +457,6 @@ func (p *ConfigParser) mergeConfigs(parent, child *Config) (*Config, error) {
     return base, nil
 }
 
-// replaceEnvVars 替换配置中的环境变量
-func (p *ConfigParser) replaceEnvVars(configStr string) string {
-	return p.envVarPattern.ReplaceAllStringFunc(configStr, func(match string) string {
-		// 提取变量名 (去掉 ${ 和 })
-		varName := match[2 : len(match)-1]
-
-		// 支持默认值语法: ${VAR_NAME:default_value}
-		parts := strings.SplitN(varName, ":", 2)
-		envVar := parts[0]
-		defaultValue := ""
-
-		if len(parts) > 1 {
-			defaultValue = parts[1]
-		}
-
-		// 获取环境变量值
-		if value := os.Getenv(envVar); value != "" {
-			return value
-		}
-
-		return defaultValue
-	})
-}
-
 // validateConfig 验证配置
 func (p *ConfigParser) validateConfig(config *Config) error {
 	if config.Name == "" {
@@ -313,6 +506,16 @@ func (p *ConfigParser) validateConfig(config *Config) error {
 		return err
 	}
 
+	// 验证输入声明
+	if err := p.validateInputs(config.Inputs); err != nil {
+		return err
+	}
+
+	// 验证组件声明的数据流
+	if err := p.validateDataFlow(config); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -424,17 +627,9 @@ func (p *ConfigParser) setDefaults(config *Config) {
 			layer.Mode = SerialMode
 		}
 
-		if layer.Enabled == false && layer.Mode != "" {
-			layer.Enabled = true // 默认启用
-		}
-
 		for j := range layer.Components {
 			component := &layer.Components[j]
 
-			if component.Enabled == false && component.Type != "" {
-				component.Enabled = true // 默认启用
-			}
-
 			if component.Timeout == 0 {
 				component.Timeout = 30 * time.Second // 默认30秒超时
 			}
@@ -480,13 +675,34 @@ func (c *Config) GetComponent(layerName, componentName string) (*ComponentConfig
 	return nil, false
 }
 
+// deepCopy 通过 JSON 往返深拷贝配置，不做验证与默认值填充
+func (c *Config) deepCopy() (*Config, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, &ConfigError{
+			Type:    "json_marshal_failed",
+			Message: fmt.Sprintf("failed to marshal config to JSON: %v", err),
+			Cause:   err,
+		}
+	}
+	var copied Config
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, &ConfigError{
+			Type:    "json_unmarshal_failed",
+			Message: fmt.Sprintf("failed to unmarshal JSON config: %v", err),
+			Cause:   err,
+		}
+	}
+	return &copied, nil
+}
+
 // Clone 克隆配置
 func (c *Config) Clone() (*Config, error) {
-	jsonStr, err := c.ToJSON()
+	copied, err := c.deepCopy()
 	if err != nil {
 		return nil, err
 	}
 
 	parser := NewConfigParser()
-	return parser.ParseBytes([]byte(jsonStr))
+	return parser.finalize(copied)
 }
@@ -0,0 +1,318 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecodeConfig 将 ComponentConfig.Config 解码为类型化的配置结构体
+//
+// 字段通过 `config:"key,required"` 标签映射（未设置时依次使用 json 标签与字段名），
+// `default:"..."` 标签为缺失的标量字段提供默认值。time.Duration 字段接受时长字符串或整数纳秒，
+// 嵌套结构体、切片、map 与指针会递归解码。所有类型不匹配与必需字段缺失
+// 会以 ValidationErrors 一次性返回，字段路径形如 config.output.path
+// 若解码后的 T 或 *T 实现了 Validate() error，则会继续调用进行自定义校验
+func DecodeConfig[T any](config ComponentConfig) (T, error) {
+	var out T
+	err := DecodeConfigMap(config.Config, &out)
+	return out, err
+}
+
+// DecodeConfigMap 将配置 map 解码到 out 指向的结构体，规则同 DecodeConfig
+func DecodeConfigMap(values map[string]interface{}, out interface{}) error {
+	if err := decodeConfigMap(values, out); err != nil {
+		return err
+	}
+	if validatable, ok := out.(interface{ Validate() error }); ok {
+		return validatable.Validate()
+	}
+	return nil
+}
+
+// decodeConfigMap 只解码而不调用 Validate，用于 Validate 另由 Layer.Validate 调用的场景
+func decodeConfigMap(values map[string]interface{}, out interface{}) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return &ConfigError{
+			Type:    "invalid_decode_target",
+			Message: fmt.Sprintf("decode target must be a non-nil pointer to struct, got %T", out),
+		}
+	}
+
+	var errs ValidationErrors
+	decodeStruct(values, rv.Elem(), "config", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// TypedConfig 可嵌入组件的类型化配置
+// 工厂调用 Decode 解码配置，解码错误会在 Validate 中返回，
+// 因此嵌入该类型的组件自动实现 ValidatableComponent，由 Layer.Validate 统一报告配置错误
+type TypedConfig[T any] struct {
+	Config T
+	err    error
+}
+
+// Decode 解码组件配置，错误延迟到 Validate 时返回
+func (c *TypedConfig[T]) Decode(config ComponentConfig) {
+	c.Config, c.err = DecodeConfig[T](config)
+}
+
+// Validate 返回解码配置时产生的错误
+func (c *TypedConfig[T]) Validate() error {
+	return c.err
+}
+
+// decodeStruct 按字段标签将 map 解码到结构体
+func decodeStruct(values map[string]interface{}, rv reflect.Value, path string, errs *ValidationErrors) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		key, required, skip := configFieldKey(field)
+		if skip {
+			continue
+		}
+		fieldPath := joinFieldPath(path, key)
+
+		raw, exists := values[key]
+		if !exists || raw == nil {
+			if def, ok := field.Tag.Lookup("default"); ok {
+				if err := decodeDefault(def, rv.Field(i)); err != nil {
+					*errs = append(*errs, &ValidationError{
+						Field:   fieldPath,
+						Value:   def,
+						Message: fmt.Sprintf("invalid default value: %v", err),
+					})
+				}
+				continue
+			}
+			if required {
+				*errs = append(*errs, &ValidationError{
+					Field:   fieldPath,
+					Message: "required field is missing",
+				})
+			}
+			continue
+		}
+
+		decodeValue(raw, rv.Field(i), fieldPath, errs)
+	}
+}
+
+// configFieldKey 解析字段对应的配置键与是否必需
+func configFieldKey(field reflect.StructField) (key string, required bool, skip bool) {
+	tag, ok := field.Tag.Lookup("config")
+	if !ok {
+		tag = field.Tag.Get("json")
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	key = parts[0]
+	if key == "" {
+		key = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "required" {
+			required = true
+		}
+	}
+	return key, required, false
+}
+
+// decodeValue 将单个配置值解码到目标字段
+func decodeValue(raw interface{}, target reflect.Value, path string, errs *ValidationErrors) {
+	mismatch := func(expected string) {
+		*errs = append(*errs, &ValidationError{
+			Field:   path,
+			Value:   raw,
+			Message: fmt.Sprintf("expected %s, got %s", expected, jsonTypeName(raw)),
+		})
+	}
+
+	if target.Type() == durationType {
+		d, err := toDuration(raw)
+		if err != nil {
+			*errs = append(*errs, &ValidationError{Field: path, Value: raw, Message: err.Error()})
+			return
+		}
+		target.SetInt(int64(d))
+		return
+	}
+
+	switch target.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(target.Type().Elem())
+		before := len(*errs)
+		decodeValue(raw, elem.Elem(), path, errs)
+		if len(*errs) == before {
+			target.Set(elem)
+		}
+	case reflect.Interface:
+		// null 解码为接口的零值；非空接口要求值实现该接口
+		if raw == nil {
+			target.Set(reflect.Zero(target.Type()))
+			return
+		}
+		value := reflect.ValueOf(raw)
+		if !value.Type().AssignableTo(target.Type()) {
+			mismatch(target.Type().String())
+			return
+		}
+		target.Set(value)
+	case reflect.String:
+		if secret, ok := raw.(Secret); ok {
+			target.SetString(secret.Value())
+			return
+		}
+		s, ok := raw.(string)
+		if !ok {
+			mismatch("string")
+			return
+		}
+		target.SetString(s)
+	case reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			mismatch("boolean")
+			return
+		}
+		target.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := toFloat64(raw)
+		if !ok || n != math.Trunc(n) {
+			mismatch("integer")
+			return
+		}
+		if target.OverflowInt(int64(n)) {
+			*errs = append(*errs, &ValidationError{Field: path, Value: raw, Message: fmt.Sprintf("value overflows %s", target.Type())})
+			return
+		}
+		target.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := toFloat64(raw)
+		if !ok || n != math.Trunc(n) || n < 0 {
+			mismatch("non-negative integer")
+			return
+		}
+		if target.OverflowUint(uint64(n)) {
+			*errs = append(*errs, &ValidationError{Field: path, Value: raw, Message: fmt.Sprintf("value overflows %s", target.Type())})
+			return
+		}
+		target.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		n, ok := toFloat64(raw)
+		if !ok {
+			mismatch("number")
+			return
+		}
+		target.SetFloat(n)
+	case reflect.Slice:
+		items := reflect.ValueOf(raw)
+		if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+			mismatch("array")
+			return
+		}
+		slice := reflect.MakeSlice(target.Type(), items.Len(), items.Len())
+		for i := 0; i < items.Len(); i++ {
+			decodeValue(items.Index(i).Interface(), slice.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+		target.Set(slice)
+	case reflect.Map:
+		obj, ok := toStringMap(raw)
+		if !ok || target.Type().Key().Kind() != reflect.String {
+			mismatch("object")
+			return
+		}
+		m := reflect.MakeMapWithSize(target.Type(), len(obj))
+		for k, v := range obj {
+			elem := reflect.New(target.Type().Elem()).Elem()
+			decodeValue(v, elem, joinFieldPath(path, k), errs)
+			m.SetMapIndex(reflect.ValueOf(k).Convert(target.Type().Key()), elem)
+		}
+		target.Set(m)
+	case reflect.Struct:
+		obj, ok := toStringMap(raw)
+		if !ok {
+			mismatch("object")
+			return
+		}
+		decodeStruct(obj, target, path, errs)
+	default:
+		*errs = append(*errs, &ValidationError{Field: path, Value: raw, Message: fmt.Sprintf("unsupported field type %s", target.Type())})
+	}
+}
+
+// decodeDefault 将 default 标签中的字符串解析到目标字段
+func decodeDefault(def string, target reflect.Value) error {
+	if target.Type() == durationType {
+		d, err := time.ParseDuration(def)
+		if err != nil {
+			return err
+		}
+		target.SetInt(int64(d))
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(def)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(def)
+		if err != nil {
+			return err
+		}
+		target.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(def, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(def, 10, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(def, target.Type().Bits())
+		if err != nil {
+			return err
+		}
+		target.SetFloat(n)
+	default:
+		return fmt.Errorf("default values are not supported for %s", target.Type())
+	}
+	return nil
+}
+
+// toDuration 将时长字符串或数值纳秒转换为 time.Duration
+func toDuration(raw interface{}) (time.Duration, error) {
+	if s, ok := raw.(string); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return d, nil
+	}
+	if d, ok := raw.(time.Duration); ok {
+		return d, nil
+	}
+	if n, ok := toFloat64(raw); ok && n == math.Trunc(n) {
+		return time.Duration(n), nil
+	}
+	return 0, fmt.Errorf("expected duration string or integer nanoseconds, got %s", jsonTypeName(raw))
+}
@@ -0,0 +1,199 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type decodeTestOutput struct {
+	Path   string `config:"path,required"`
+	Append bool   `config:"append"`
+}
+
+type decodeTestConfig struct {
+	FilePath string            `config:"file_path,required"`
+	Encoding string            `config:"encoding" default:"utf-8"`
+	Retries  int               `config:"retries" default:"3"`
+	Ratio    float64           `config:"ratio"`
+	Timeout  time.Duration     `config:"timeout" default:"5s"`
+	Tags     []string          `config:"tags"`
+	Labels   map[string]string `config:"labels"`
+	Output   *decodeTestOutput `config:"output"`
+	Legacy   string            `json:"legacy_name"`
+	Ignored  string            `config:"-"`
+}
+
+type validatedDecodeConfig struct {
+	Min int `config:"min"`
+	Max int `config:"max"`
+}
+
+func (c validatedDecodeConfig) Validate() error {
+	if c.Min > c.Max {
+		return &ValidationError{Field: "config.min", Value: c.Min, Message: "min must not exceed max"}
+	}
+	return nil
+}
+
+func TestDecodeConfig(t *testing.T) {
+	t.Run("Decode values and defaults", func(t *testing.T) {
+		cfg, err := DecodeConfig[decodeTestConfig](ComponentConfig{
+			Name: "reader",
+			Config: map[string]interface{}{
+				"file_path":   "data.txt",
+				"retries":     float64(5),
+				"ratio":       0.5,
+				"tags":        []interface{}{"a", "b"},
+				"labels":      map[string]interface{}{"team": "core"},
+				"output":      map[string]interface{}{"path": "out.txt", "append": true},
+				"legacy_name": "old",
+				"Ignored":     "x",
+			},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.FilePath != "data.txt" || cfg.Encoding != "utf-8" || cfg.Retries != 5 || cfg.Ratio != 0.5 {
+			t.Errorf("Unexpected scalar values: %+v", cfg)
+		}
+		if cfg.Timeout != 5*time.Second {
+			t.Errorf("Expected default timeout 5s, got %v", cfg.Timeout)
+		}
+		if len(cfg.Tags) != 2 || cfg.Labels["team"] != "core" || cfg.Legacy != "old" || cfg.Ignored != "" {
+			t.Errorf("Unexpected collection values: %+v", cfg)
+		}
+		if cfg.Output == nil || cfg.Output.Path != "out.txt" || !cfg.Output.Append {
+			t.Errorf("Unexpected nested value: %+v", cfg.Output)
+		}
+	})
+
+	t.Run("Parse durations", func(t *testing.T) {
+		cfg, err := DecodeConfig[decodeTestConfig](ComponentConfig{Config: map[string]interface{}{
+			"file_path": "a", "timeout": "1m30s",
+		}})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.Timeout != 90*time.Second {
+			t.Errorf("Expected 1m30s, got %v", cfg.Timeout)
+		}
+	})
+
+	t.Run("Report all errors with field paths", func(t *testing.T) {
+		_, err := DecodeConfig[decodeTestConfig](ComponentConfig{Config: map[string]interface{}{
+			"retries": 1.5,
+			"tags":    []interface{}{"ok", 1},
+			"timeout": "soon",
+			"output":  map[string]interface{}{"append": "yes"},
+		}})
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("Expected ValidationErrors, got %T (%v)", err, err)
+		}
+		expected := []string{"config.file_path", "config.retries", "config.timeout", "config.tags[1]", "config.output.path", "config.output.append"}
+		if len(errs) != len(expected) {
+			t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errs), errs)
+		}
+		for i, field := range expected {
+			if errs[i].Field != field {
+				t.Errorf("Expected error %d on %s, got %s", i, field, errs[i].Field)
+			}
+		}
+	})
+
+	t.Run("Run custom validation", func(t *testing.T) {
+		_, err := DecodeConfig[validatedDecodeConfig](ComponentConfig{Config: map[string]interface{}{"min": 3.0, "max": 1.0}})
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Field != "config.min" {
+			t.Errorf("Expected custom validation error, got %v", err)
+		}
+	})
+
+	t.Run("Decode null into interfaces", func(t *testing.T) {
+		type anyConfig struct {
+			Tags   []interface{}          `config:"tags"`
+			Extra  map[string]interface{} `config:"extra"`
+			Fields interface{}            `config:"fields"`
+		}
+		cfg, err := DecodeConfig[anyConfig](ComponentConfig{Config: map[string]interface{}{
+			"tags":   []interface{}{"a", nil},
+			"extra":  map[string]interface{}{"note": nil},
+			"fields": nil,
+		}})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(cfg.Tags) != 2 || cfg.Tags[1] != nil || cfg.Fields != nil {
+			t.Errorf("Unexpected values: %+v", cfg)
+		}
+		if v, ok := cfg.Extra["note"]; !ok || v != nil {
+			t.Errorf("Expected a nil map value, got %v %v", v, ok)
+		}
+	})
+
+	t.Run("Reject values not implementing the interface", func(t *testing.T) {
+		type stringerConfig struct {
+			Name fmt.Stringer `config:"name"`
+		}
+		_, err := DecodeConfig[stringerConfig](ComponentConfig{Config: map[string]interface{}{"name": "x"}})
+		var errs ValidationErrors
+		if !errors.As(err, &errs) || errs[0].Field != "config.name" {
+			t.Errorf("Expected a ValidationError for config.name, got %v", err)
+		}
+	})
+
+	t.Run("Reject invalid target", func(t *testing.T) {
+		var s string
+		if err := DecodeConfigMap(nil, &s); err == nil {
+			t.Error("Expected error for non-struct target")
+		}
+	})
+}
+
+// typedConfigComponent 嵌入 TypedConfig 的测试组件
+type typedConfigComponent struct {
+	MockComponent
+	TypedConfig[decodeTestConfig]
+}
+
+func TestTypedConfigLayerValidate(t *testing.T) {
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "typed",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			comp := &typedConfigComponent{MockComponent: MockComponent{name: config.Name}}
+			comp.Decode(config)
+			return comp, nil
+		},
+	})
+
+	t.Run("Valid config", func(t *testing.T) {
+		layer, err := NewLayer(LayerConfig{Name: "L1", Mode: SerialMode, Components: []ComponentConfig{
+			{Name: "c1", Type: "typed", Config: map[string]interface{}{"file_path": "a.txt"}},
+		}}, registry)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := layer.Validate(); err != nil {
+			t.Errorf("Expected validation to pass, got %v", err)
+		}
+		if comp := layer.Components()[0].(*typedConfigComponent); comp.Config.FilePath != "a.txt" {
+			t.Errorf("Expected decoded file path, got %q", comp.Config.FilePath)
+		}
+	})
+
+	t.Run("Invalid config", func(t *testing.T) {
+		layer, err := NewLayer(LayerConfig{Name: "L1", Mode: SerialMode, Components: []ComponentConfig{
+			{Name: "c1", Type: "typed", Config: map[string]interface{}{"file_path": 42}},
+		}}, registry)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		var errs ValidationErrors
+		if err := layer.Validate(); !errors.As(err, &errs) || errs[0].Field != "config.file_path" {
+			t.Errorf("Expected ValidationErrors for config.file_path, got %v", err)
+		}
+	})
+}
@@ -1,44 +1,45 @@
 package engine
 
 import (
-    "os"
-    "path/filepath"
-    "strings"
-    "testing"
-    "time"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
 )
 
 func writeFile(t *testing.T, dir, name, content string) string {
-    t.Helper()
-    p := filepath.Join(dir, name)
-    if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
-        t.Fatalf("write file %s failed: %v", p, err)
-    }
-    return p
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file %s failed: %v", p, err)
+	}
+	return p
 }
 
 func findLayer(cfg *Config, name string) *LayerConfig {
-    for i := range cfg.Layers {
-        if cfg.Layers[i].Name == name {
-            return &cfg.Layers[i]
-        }
-    }
-    return nil
+	for i := range cfg.Layers {
+		if cfg.Layers[i].Name == name {
+			return &cfg.Layers[i]
+		}
+	}
+	return nil
 }
 
 func findComponent(l *LayerConfig, name string) *ComponentConfig {
-    for i := range l.Components {
-        if l.Components[i].Name == name {
-            return &l.Components[i]
-        }
-    }
-    return nil
+	for i := range l.Components {
+		if l.Components[i].Name == name {
+			return &l.Components[i]
+		}
+	}
+	return nil
 }
 
 func TestConfigExtendsMerge_AddDeletePatch(t *testing.T) {
-    dir := t.TempDir()
+	dir := t.TempDir()
 
-    parent := `{
+	parent := `{
         "name": "workflow-a",
         "layers": [
           {"name": "L1", "mode": "serial", "components": [
@@ -50,7 +51,7 @@ func TestConfigExtendsMerge_AddDeletePatch(t *testing.T) {
           ]}
         ]
     }`
-    child := `{
+	child := `{
         "extends": "PARENT",
         "name": "workflow-b",
         "layers": [
@@ -66,75 +67,87 @@ func TestConfigExtendsMerge_AddDeletePatch(t *testing.T) {
         ]
     }`
 
-    parentPath := writeFile(t, dir, "parent.json", parent)
-    childPath := writeFile(t, dir, "child.json", child)
-
-    // inject real parent path into child content
-    b, err := os.ReadFile(childPath)
-    if err != nil { t.Fatalf("read child: %v", err) }
-    // Replace placeholder with actual parent path
-    childContent := strings.ReplaceAll(string(b), "\"PARENT\"", "\""+parentPath+"\"")
-    if err := os.WriteFile(childPath, []byte(childContent), 0o644); err != nil {
-        t.Fatalf("rewrite child with path: %v", err)
-    }
-
-    parser := NewConfigParser()
-    cfg, err := parser.ParseFile(childPath)
-    if err != nil {
-        t.Fatalf("ParseFile(child) failed: %v", err)
-    }
-
-    if cfg.Name != "workflow-b" {
-        t.Fatalf("name not overridden, got %s", cfg.Name)
-    }
-
-    // L1 removed
-    if l := findLayer(cfg, "L1"); l != nil {
-        t.Fatalf("L1 should be removed")
-    }
-    // L2 patched
-    l2 := findLayer(cfg, "L2")
-    if l2 == nil { t.Fatalf("L2 not found") }
-    if l2.Parallel != 8 { t.Fatalf("L2.parallel expected 8, got %d", l2.Parallel) }
-    // C2 updated
-    c2 := findComponent(l2, "C2")
-    if c2 == nil { t.Fatalf("C2 not found") }
-    if c2.Config == nil || c2.Config["threshold"] != 0.9 {
-        t.Fatalf("C2.config.threshold expected 0.9, got %v", c2.Config["threshold"])
-    }
-    if c2.Retry == nil || c2.Retry.MaxRetries != 3 || c2.Retry.Delay != 2000000000 || c2.Retry.Backoff != 2.0 {
-        t.Fatalf("C2.retry not overridden correctly: %+v", c2.Retry)
-    }
-    // C_new added
-    if cnew := findComponent(l2, "C_new"); cnew == nil {
-        t.Fatalf("C_new should be added")
-    }
-    // L3 added
-    if l := findLayer(cfg, "L3"); l == nil {
-        t.Fatalf("L3 should be added")
-    }
+	parentPath := writeFile(t, dir, "parent.json", parent)
+	childPath := writeFile(t, dir, "child.json", child)
+
+	// inject real parent path into child content
+	b, err := os.ReadFile(childPath)
+	if err != nil {
+		t.Fatalf("read child: %v", err)
+	}
+	// Replace placeholder with actual parent path
+	childContent := strings.ReplaceAll(string(b), "\"PARENT\"", "\""+parentPath+"\"")
+	if err := os.WriteFile(childPath, []byte(childContent), 0o644); err != nil {
+		t.Fatalf("rewrite child with path: %v", err)
+	}
+
+	parser := NewConfigParser()
+	cfg, err := parser.ParseFile(childPath)
+	if err != nil {
+		t.Fatalf("ParseFile(child) failed: %v", err)
+	}
+
+	if cfg.Name != "workflow-b" {
+		t.Fatalf("name not overridden, got %s", cfg.Name)
+	}
+
+	// L1 removed
+	if l := findLayer(cfg, "L1"); l != nil {
+		t.Fatalf("L1 should be removed")
+	}
+	// L2 patched
+	l2 := findLayer(cfg, "L2")
+	if l2 == nil {
+		t.Fatalf("L2 not found")
+	}
+	if l2.Parallel != 8 {
+		t.Fatalf("L2.parallel expected 8, got %d", l2.Parallel)
+	}
+	// C2 updated
+	c2 := findComponent(l2, "C2")
+	if c2 == nil {
+		t.Fatalf("C2 not found")
+	}
+	if c2.Config == nil || c2.Config["threshold"] != 0.9 {
+		t.Fatalf("C2.config.threshold expected 0.9, got %v", c2.Config["threshold"])
+	}
+	if c2.Retry == nil || c2.Retry.MaxRetries != 3 || c2.Retry.Delay != 2000000000 || c2.Retry.Backoff != 2.0 {
+		t.Fatalf("C2.retry not overridden correctly: %+v", c2.Retry)
+	}
+	// C_new added
+	if cnew := findComponent(l2, "C_new"); cnew == nil {
+		t.Fatalf("C_new should be added")
+	}
+	// L3 added
+	if l := findLayer(cfg, "L3"); l == nil {
+		t.Fatalf("L3 should be added")
+	}
 }
 
 func TestConfigExtendsCycleDetection(t *testing.T) {
-    dir := t.TempDir()
-    aPath := filepath.Join(dir, "a.json")
-    bPath := filepath.Join(dir, "b.json")
-    a := `{"name":"A","extends":"` + bPath + `","layers":[]}`
-    b := `{"name":"B","extends":"` + aPath + `","layers":[]}`
-    if err := os.WriteFile(aPath, []byte(a), 0o644); err != nil { t.Fatalf("write a: %v", err) }
-    if err := os.WriteFile(bPath, []byte(b), 0o644); err != nil { t.Fatalf("write b: %v", err) }
-
-    parser := NewConfigParser()
-    _, err := parser.ParseFile(aPath)
-    if err == nil {
-        t.Fatalf("expected cycle detection error, got nil")
-    }
+	dir := t.TempDir()
+	aPath := filepath.Join(dir, "a.json")
+	bPath := filepath.Join(dir, "b.json")
+	a := `{"name":"A","extends":"` + bPath + `","layers":[]}`
+	b := `{"name":"B","extends":"` + aPath + `","layers":[]}`
+	if err := os.WriteFile(aPath, []byte(a), 0o644); err != nil {
+		t.Fatalf("write a: %v", err)
+	}
+	if err := os.WriteFile(bPath, []byte(b), 0o644); err != nil {
+		t.Fatalf("write b: %v", err)
+	}
+
+	parser := NewConfigParser()
+	_, err := parser.ParseFile(aPath)
+	if err == nil {
+		t.Fatalf("expected cycle detection error, got nil")
+	}
 }
 
 func TestDefaultComponentTimeoutAndRemove(t *testing.T) {
-    parser := NewConfigParser()
-    // parent with two components, child removes one; also missing timeout should default to 30s
-    parent := `{
+	parser := NewConfigParser()
+	// parent with two components, child removes one; also missing timeout should default to 30s
+	parent := `{
       "name": "base",
       "layers": [
         { "name": "L1", "mode": "serial", "components": [
@@ -143,7 +156,7 @@ func TestDefaultComponentTimeoutAndRemove(t *testing.T) {
         ]}
       ]
     }`
-    child := `{
+	child := `{
       "extends": "MEM",
       "name": "derived",
       "layers": [
@@ -153,19 +166,261 @@ func TestDefaultComponentTimeoutAndRemove(t *testing.T) {
       ]
     }`
 
-    dir := t.TempDir()
-    parentPath := writeFile(t, dir, "p.json", parent)
-    childPath := writeFile(t, dir, "c.json", child)
-    cb, _ := os.ReadFile(childPath)
-    cc := strings.ReplaceAll(string(cb), "\"MEM\"", "\""+parentPath+"\"")
-    if err := os.WriteFile(childPath, []byte(cc), 0o644); err != nil { t.Fatalf("rewrite child: %v", err) }
-
-    cfg, err := parser.ParseFile(childPath)
-    if err != nil { t.Fatalf("parse child: %v", err) }
-    l1 := findLayer(cfg, "L1")
-    if l1 == nil { t.Fatalf("L1 not found") }
-    if findComponent(l1, "C1") != nil { t.Fatalf("C1 should be removed") }
-    c2 := findComponent(l1, "C2")
-    if c2 == nil { t.Fatalf("C2 not found") }
-    if c2.Timeout != 30*time.Second { t.Fatalf("C2 timeout default expected 30s, got %v", c2.Timeout) }
-}
\ No newline at end of file
+	dir := t.TempDir()
+	parentPath := writeFile(t, dir, "p.json", parent)
+	childPath := writeFile(t, dir, "c.json", child)
+	cb, _ := os.ReadFile(childPath)
+	cc := strings.ReplaceAll(string(cb), "\"MEM\"", "\""+parentPath+"\"")
+	if err := os.WriteFile(childPath, []byte(cc), 0o644); err != nil {
+		t.Fatalf("rewrite child: %v", err)
+	}
+
+	cfg, err := parser.ParseFile(childPath)
+	if err != nil {
+		t.Fatalf("parse child: %v", err)
+	}
+	l1 := findLayer(cfg, "L1")
+	if l1 == nil {
+		t.Fatalf("L1 not found")
+	}
+	if findComponent(l1, "C1") != nil {
+		t.Fatalf("C1 should be removed")
+	}
+	c2 := findComponent(l1, "C2")
+	if c2 == nil {
+		t.Fatalf("C2 not found")
+	}
+	if c2.Timeout != 30*time.Second {
+		t.Fatalf("C2 timeout default expected 30s, got %v", c2.Timeout)
+	}
+}
+func TestConfigExtendsExplicitFalse(t *testing.T) {
+	dir := t.TempDir()
+	parent := `{
+      "name": "base",
+      "layers": [
+        { "name": "L1", "components": [
+          {"name": "C1", "type": "X", "critical": true},
+          {"name": "C2", "type": "Y"}
+        ]},
+        { "name": "L2", "components": [ {"name": "C3", "type": "Z"} ]}
+      ]
+    }`
+	parentPath := writeFile(t, dir, "p.json", parent)
+	child := `{
+      "extends": "` + parentPath + `",
+      "name": "derived",
+      "layers": [
+        { "name": "L1", "components": [
+          {"name": "C1", "critical": false},
+          {"name": "C2", "enabled": false}
+        ]},
+        { "name": "L2", "enabled": false }
+      ]
+    }`
+	childPath := writeFile(t, dir, "c.json", child)
+
+	cfg, err := NewConfigParser().ParseFile(childPath)
+	if err != nil {
+		t.Fatalf("parse child: %v", err)
+	}
+	l1 := findLayer(cfg, "L1")
+	if l1 == nil || !l1.IsEnabled() {
+		t.Fatalf("L1 should remain enabled")
+	}
+	if c1 := findComponent(l1, "C1"); c1 == nil || c1.IsCritical() {
+		t.Fatalf("C1 critical should be overridden to false")
+	}
+	if c2 := findComponent(l1, "C2"); c2 == nil || c2.IsEnabled() {
+		t.Fatalf("C2 should be disabled")
+	}
+	if l2 := findLayer(cfg, "L2"); l2 == nil || l2.IsEnabled() {
+		t.Fatalf("L2 should be disabled")
+	}
+}
+
+func TestConfigExplicitFalseWithoutExtends(t *testing.T) {
+	data := `{
+      "name": "plain",
+      "layers": [
+        { "name": "L1", "enabled": false, "components": [
+          {"name": "C1", "type": "X", "enabled": false},
+          {"name": "C2", "type": "Y"}
+        ]}
+      ]
+    }`
+	cfg, err := NewConfigParser().ParseBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	l1 := findLayer(cfg, "L1")
+	if l1.IsEnabled() {
+		t.Fatalf("L1 enabled=false should be honoured")
+	}
+	if findComponent(l1, "C1").IsEnabled() {
+		t.Fatalf("C1 enabled=false should be honoured")
+	}
+	if !findComponent(l1, "C2").IsEnabled() {
+		t.Fatalf("C2 should default to enabled")
+	}
+
+	clone, err := cfg.Clone()
+	if err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	if findLayer(clone, "L1").IsEnabled() {
+		t.Fatalf("enabled=false should survive Clone")
+	}
+}
+
+func TestConfigExtendsRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "base"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "teams"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "base"), "root.json", `{
+      "name": "root",
+      "layers": [ {"name": "L1", "components": [ {"name": "C1", "type": "X", "config": {"a": 1}} ]} ]
+    }`)
+	// 中间层只是一个片段：自身没有 name，相对路径相对于 base 目录
+	writeFile(t, filepath.Join(dir, "base"), "mid.json", `{
+      "extends": "root.json",
+      "layers": [ {"name": "L1", "components": [ {"name": "C1", "config": {"b": 2}} ]} ]
+    }`)
+	childPath := writeFile(t, filepath.Join(dir, "teams"), "child.json", `{
+      "extends": "../base/mid.json",
+      "name": "child"
+    }`)
+
+	cfg, err := NewConfigParser().ParseFile(childPath)
+	if err != nil {
+		t.Fatalf("parse child: %v", err)
+	}
+	c1 := findComponent(findLayer(cfg, "L1"), "C1")
+	if c1 == nil || c1.Config["a"] != 1.0 || c1.Config["b"] != 2.0 {
+		t.Fatalf("C1 config not merged across relative extends: %+v", c1)
+	}
+	if len(cfg.Extends) != 0 {
+		t.Fatalf("merged config should not keep extends, got %v", cfg.Extends)
+	}
+}
+
+func TestConfigExtendsMultipleParents(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.json", `{
+      "name": "a", "timeout": "10s", "global": {"owner": "a", "region": "us"},
+      "layers": [ {"name": "L1", "components": [ {"name": "C1", "type": "X"} ]} ]
+    }`)
+	writeFile(t, dir, "b.json", `{
+      "global": {"owner": "b"},
+      "layers": [
+        {"name": "L1", "components": [ {"name": "C1", "type": "Y", "critical": true} ]},
+        {"name": "L2", "components": [ {"name": "C2", "type": "Z"} ]}
+      ]
+    }`)
+	childPath := writeFile(t, dir, "child.json", `{
+      "extends": ["a.json", "b.json"],
+      "name": "child"
+    }`)
+
+	cfg, err := NewConfigParser().ParseFile(childPath)
+	if err != nil {
+		t.Fatalf("parse child: %v", err)
+	}
+	if cfg.Timeout != 10*time.Second {
+		t.Fatalf("timeout from first parent expected, got %v", cfg.Timeout)
+	}
+	if cfg.Global["owner"] != "b" || cfg.Global["region"] != "us" {
+		t.Fatalf("later parent should override earlier one, got %v", cfg.Global)
+	}
+	c1 := findComponent(findLayer(cfg, "L1"), "C1")
+	if c1 == nil || c1.Type != "Y" || !c1.IsCritical() {
+		t.Fatalf("C1 should be patched by second parent: %+v", c1)
+	}
+	if findLayer(cfg, "L2") == nil {
+		t.Fatalf("L2 from second parent should be added")
+	}
+	if len(cfg.Layers) != 2 || cfg.Layers[0].Name != "L1" {
+		t.Fatalf("unexpected layer order: %+v", cfg.Layers)
+	}
+}
+
+func TestConfigExtendsParserReuseAndDiamond(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "common.json", `{
+      "name": "common",
+      "layers": [ {"name": "L1", "components": [ {"name": "C1", "type": "X"} ]} ]
+    }`)
+	writeFile(t, dir, "left.json", `{"extends": "common.json", "metadata": {"left": "yes"}}`)
+	writeFile(t, dir, "right.json", `{"extends": "common.json", "metadata": {"right": "yes"}}`)
+	childPath := writeFile(t, dir, "child.json", `{"extends": ["left.json", "right.json"], "name": "diamond"}`)
+
+	parser := NewConfigParser()
+	for i := 0; i < 2; i++ {
+		cfg, err := parser.ParseFile(childPath)
+		if err != nil {
+			t.Fatalf("parse #%d: %v", i+1, err)
+		}
+		if cfg.Metadata["left"] != "yes" || cfg.Metadata["right"] != "yes" {
+			t.Fatalf("parse #%d: metadata not merged: %v", i+1, cfg.Metadata)
+		}
+	}
+}
+
+func TestConfigParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"workflows/base.json": {Data: []byte(`{
+          "name": "base",
+          "layers": [ {"name": "L1", "components": [ {"name": "C1", "type": "X"} ]} ]
+        }`)},
+		"workflows/prod/child.json": {Data: []byte(`{
+          "extends": "../base.json",
+          "name": "prod",
+          "layers": [ {"name": "L1", "components": [ {"name": "C1", "timeout": "1m"} ]} ]
+        }`)},
+		"workflows/loop.json": {Data: []byte(`{"name": "loop", "extends": "loop.json"}`)},
+	}
+
+	parser := NewConfigParser()
+	cfg, err := parser.ParseFS(fsys, "workflows/prod/child.json")
+	if err != nil {
+		t.Fatalf("ParseFS: %v", err)
+	}
+	c1 := findComponent(findLayer(cfg, "L1"), "C1")
+	if cfg.Name != "prod" || c1 == nil || c1.Type != "X" || c1.Timeout != time.Minute {
+		t.Fatalf("unexpected merged config: %+v", cfg)
+	}
+
+	_, err = parser.ParseFS(fsys, "workflows/loop.json")
+	if configErr, ok := err.(*ConfigError); !ok || configErr.Type != "extends_cycle_detected" {
+		t.Fatalf("expected extends_cycle_detected, got %v", err)
+	}
+}
+
+func TestMergeConfigsCopiesBoolValues(t *testing.T) {
+	parent := &Config{Name: "base", Layers: []LayerConfig{
+		{Name: "L1", Components: []ComponentConfig{{Name: "C1", Type: "X"}}},
+	}}
+	child := &Config{Layers: []LayerConfig{
+		{Name: "L1", Enabled: BoolPtr(false), Components: []ComponentConfig{
+			{Name: "C1", Enabled: BoolPtr(false), Critical: BoolPtr(true)},
+		}},
+	}}
+
+	merged, err := NewConfigParser().mergeConfigs(parent, child)
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	l1 := findLayer(merged, "L1")
+	c1 := findComponent(l1, "C1")
+	if l1.IsEnabled() || c1.IsEnabled() || !c1.IsCritical() {
+		t.Fatalf("expected child overrides to apply, got %+v", l1)
+	}
+	if l1.Enabled == child.Layers[0].Enabled || c1.Enabled == child.Layers[0].Components[0].Enabled || c1.Critical == child.Layers[0].Components[0].Critical {
+		t.Fatal("expected merged config not to share bool pointers with the child")
+	}
+}
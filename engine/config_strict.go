@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// deprecatedConfigFields 已废弃的配置字段及其迁移提示
+var deprecatedConfigFields = map[string]string{
+	"is_core":        `use "critical" instead`,
+	"execution_mode": `use "mode" instead`,
+}
+
+// DeprecatedConfigFields 返回已知的废弃字段及其迁移提示
+func DeprecatedConfigFields() map[string]string {
+	fields := make(map[string]string, len(deprecatedConfigFields))
+	for k, v := range deprecatedConfigFields {
+		fields[k] = v
+	}
+	return fields
+}
+
+// unknownField 描述一个未知字段
+type unknownField struct {
+	path string
+	name string
+}
+
+// checkUnknownFields 检查解析后的 JSON 中是否存在 Config 未定义的字段
+func (p *ConfigParser) checkUnknownFields(raw interface{}) error {
+	var unknown []unknownField
+	collectUnknownFields(raw, reflect.TypeOf(Config{}), "", &unknown)
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	// 全部为废弃字段时使用 deprecated_field 类型，便于调用方区分迁移问题与拼写错误
+	messages := make([]string, 0, len(unknown))
+	errType := "deprecated_field"
+	for _, f := range unknown {
+		if hint, ok := deprecatedConfigFields[f.name]; ok {
+			messages = append(messages, fmt.Sprintf("deprecated field %s: %s", f.path, hint))
+		} else {
+			messages = append(messages, fmt.Sprintf("unknown field %s", f.path))
+			errType = "unknown_field"
+		}
+	}
+
+	return &ConfigError{
+		Type:    errType,
+		Field:   unknown[0].path,
+		Message: strings.Join(messages, "; "),
+	}
+}
+
+// collectUnknownFields 递归比对 JSON 值与目标类型，记录未知字段的 JSON 路径
+func collectUnknownFields(value interface{}, t reflect.Type, path string, unknown *[]unknownField) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		fields := jsonFields(t)
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fieldPath := joinFieldPath(path, k)
+			ft, ok := fields[k]
+			if !ok {
+				*unknown = append(*unknown, unknownField{path: fieldPath, name: k})
+				continue
+			}
+			collectUnknownFields(obj[k], ft, fieldPath, unknown)
+		}
+	case reflect.Map:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			collectUnknownFields(obj[k], t.Elem(), joinFieldPath(path, k), unknown)
+		}
+	case reflect.Slice, reflect.Array:
+		arr, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+		for i, item := range arr {
+			collectUnknownFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), unknown)
+		}
+	}
+}
+
+// jsonFields 返回结构体 JSON 字段名到字段类型的映射
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// joinFieldPath 拼接 JSON 字段路径
+func joinFieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigStrictMode(t *testing.T) {
+	data := `{
+		"name": "strict",
+		"layers": [
+			{"name": "L1", "components": [
+				{"name": "C1", "type": "X", "is_core": true, "config": {"anything": 1}}
+			]}
+		]
+	}`
+
+	t.Run("Lenient parser ignores unknown fields", func(t *testing.T) {
+		if _, err := NewConfigParser().ParseBytes([]byte(data)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("Strict parser reports deprecated field with hint", func(t *testing.T) {
+		_, err := NewConfigParser(WithStrictMode()).ParseBytes([]byte(data))
+		configErr, ok := err.(*ConfigError)
+		if !ok {
+			t.Fatalf("Expected ConfigError, got %T (%v)", err, err)
+		}
+		if configErr.Type != "deprecated_field" {
+			t.Errorf("Expected type deprecated_field, got %s", configErr.Type)
+		}
+		if configErr.Field != "layers[0].components[0].is_core" {
+			t.Errorf("Unexpected field path: %s", configErr.Field)
+		}
+		if !strings.Contains(configErr.Message, `use "critical" instead`) {
+			t.Errorf("Expected migration hint in message, got %s", configErr.Message)
+		}
+	})
+
+	t.Run("Strict parser reports unknown fields with paths", func(t *testing.T) {
+		typo := `{"name": "strict", "timout": "5s", "layers": [
+			{"name": "L1", "execution_mode": "serial", "components": [
+				{"name": "C1", "type": "X", "retry": {"max_retries": 1, "dealy": "1s"}}
+			]}
+		]}`
+		_, err := NewConfigParser(WithStrictMode()).ParseBytes([]byte(typo))
+		configErr, ok := err.(*ConfigError)
+		if !ok {
+			t.Fatalf("Expected ConfigError, got %T (%v)", err, err)
+		}
+		if configErr.Type != "unknown_field" {
+			t.Errorf("Expected type unknown_field, got %s", configErr.Type)
+		}
+		for _, want := range []string{
+			"unknown field layers[0].components[0].retry.dealy",
+			`deprecated field layers[0].execution_mode: use "mode" instead`,
+			"unknown field timout",
+		} {
+			if !strings.Contains(configErr.Message, want) {
+				t.Errorf("Expected message to contain %q, got %s", want, configErr.Message)
+			}
+		}
+	})
+
+	t.Run("Strict parser accepts known fields", func(t *testing.T) {
+		valid := `{"name": "ok", "timeout": "1s", "global": {"x": 1}, "metadata": {"k": "v"}, "layers": [
+			{"name": "L1", "mode": "serial", "enabled": true, "parallel": 2, "components": [
+				{"name": "C1", "type": "X", "critical": true, "config": {"free": {"form": true}}}
+			]}
+		]}`
+		if _, err := NewConfigParser(WithStrictMode()).ParseBytes([]byte(valid)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	})
+}
+
+func TestConfigStrictModeProfiles(t *testing.T) {
+	data := `{"name": "strict", "layers": [{"name": "L1", "components": [{"name": "C1", "type": "X"}]}],
+		"profiles": {"prod": {"layers": [{"name": "L1", "paralel": 4}]}}}`
+	_, err := NewConfigParser(WithStrictMode()).ParseBytes([]byte(data))
+	configErr, ok := err.(*ConfigError)
+	if !ok || configErr.Field != "profiles.prod.layers[0].paralel" {
+		t.Fatalf("Expected unknown field inside profile, got %v", err)
+	}
+}
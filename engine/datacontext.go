@@ -1,7 +1,13 @@
 package engine
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 // DataContext 定义组件间共享数据的并发安全接口
@@ -16,23 +22,77 @@ type DataContext interface {
 	Delete(key string)
 	Has(key string) bool
 	Snapshot() map[string]interface{}
+
+	// Update 原子地读取并更新键：fn 接收旧值及其是否存在，返回值写入键并作为结果返回
+	// fn 在持有写锁时执行，不能再调用同一 DataContext 的方法；结果需要写入 blob 存储时 fn 可能被调用多次
+	Update(key string, fn func(old interface{}, ok bool) interface{}) interface{}
+	// CompareAndSwap 仅当键存在且当前值等于 old（reflect.DeepEqual）时写入 new
+	CompareAndSwap(key string, old, new interface{}) bool
+	// SetIfAbsent 键不存在时写入 value；返回键的当前值以及本次是否写入
+	SetIfAbsent(key string, value interface{}) (actual interface{}, stored bool)
+	// Incr 原子地为整数计数器加上 delta 并返回新值（以 int64 存储），键不存在时从 0 开始
+	// 已有值不是整数（JSON 解码得到的整数值 float64 也可接受）时返回 DataContextError
+	Incr(key string, delta int64) (int64, error)
+	// Append 原子地向列表追加元素并返回新长度，键不存在时创建 []interface{}
+	// 已有值为其他类型的切片（如 []string）时按元素类型追加，类型不匹配时返回 DataContextError
+	Append(key string, values ...interface{}) (int, error)
+
+	// Scope 返回名为 name 的子作用域（同名多次调用返回同一作用域）
+	// 子作用域读取时先查本地再逐级查父作用域，写入只作用于本地，需通过 Export 发布到父作用域
+	Scope(name string) DataContext
+	// Export 将本作用域中的键发布到父作用域，根上下文调用时返回 DataContextError
+	Export(keys ...string) error
+
+	// Wait 阻塞直到 key 可见并返回其值；key 已存在时立即返回
+	// 返回的是触发返回的那次写入的值，并发写入时可能已不是最新值，需要最新值时再调用 Get
+	// ctx 结束时返回 DataContextError（wait_cancelled），可通过 errors.Is 判断 ctx 的错误
+	Wait(ctx context.Context, key string) (interface{}, error)
+	// Watch 返回 key 的变更通道（key 为空时观察所有键），通道在 ctx 结束后关闭
+	// 同一 goroutine 的写入按写入顺序投递；通知在写锁释放后发出，并发写入方的变更之间不保证顺序
+	// 变更不会因消费者缓慢而丢失或阻塞写入方：未投递的变更在内存中排队，队列没有上限，消费者应及时读取
+	Watch(ctx context.Context, key string) <-chan Change
+
+	// SetWithCleanup 写入键并登记清理函数（如关闭文件句柄、删除临时路径），
+	// 引擎在 Execute 结束时（包括失败与取消）按登记的逆序调用，键被覆盖或删除不影响清理
+	SetWithCleanup(key string, value interface{}, cleanup func())
+	// SetWithTTL 写入键，ttl 之后键不再可见；过期的键在本作用域下一次写入时删除，不产生变更通知。再次写入该键会清除 TTL
+	SetWithTTL(key string, value interface{}, ttl time.Duration)
 }
 
 // defaultDataContext 是 DataContext 的默认实现
-// 使用 RWMutex 保护内部 map 的并发访问
+// 数据保存在共享的 dataStore 中；writer 非空时为引擎绑定到某次组件执行的视图，
+// 该视图的写入会标注写入者（见 bindWriter）
 type defaultDataContext struct {
-	mu   sync.RWMutex
-	data map[string]interface{}
+	*dataStore
+	writer *ExecutionInfo
+}
+
+// dataStore 一个作用域的存储，使用 RWMutex 保护内部 map 的并发访问；
+// 作为子作用域的本地存储时，parent 非空，读取未命中会继续查询父作用域
+type dataStore struct {
+	mu       sync.RWMutex
+	data     map[string]interface{}
+	scopes   map[string]*scopedDataContext
+	parent   DataContext
+	path     string
+	watchers watcherSet
+	bubble   func(Change)             // 将本作用域的变更继续通知给所在作用域树的上级，见 notifyTree
+	tracked  bool                     // 记录写入历史，见 NewTrackedDataContext
+	history  map[string][]WriteRecord // 本作用域各键的写入历史
+	blobs    *blobConfig              // 大值存储，未设置时使用父作用域的，见 AttachBlobStore
+	expires  map[string]time.Time     // 设置了 TTL 的键的过期时间，见 SetWithTTL
+	cleanups *cleanupStack            // 清理函数，只在根上下文中保存，见 SetWithCleanup
+	schemas  *schemaConfig            // 键约束，未设置时使用父作用域的，见 AttachDataSchemas
 }
 
 // NewDataContext 创建一个空的并发安全数据上下文
 func NewDataContext() DataContext {
-	return &defaultDataContext{data: make(map[string]interface{})}
+	return &defaultDataContext{dataStore: &dataStore{data: make(map[string]interface{})}}
 }
 
 // NewDataContextWith 初始化一个并发安全数据上下文
 func NewDataContextWith(initial map[string]interface{}) DataContext {
-	ctx := &defaultDataContext{data: make(map[string]interface{}, len(initial))}
+	ctx := &defaultDataContext{dataStore: &dataStore{data: make(map[string]interface{}, len(initial))}}
 	for k, v := range initial {
 		ctx.data[k] = v
 	}
@@ -40,22 +100,19 @@ func NewDataContextWith(initial map[string]interface{}) DataContext {
 }
 
 func (c *defaultDataContext) Set(key string, value interface{}) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.data[key] = value
+	c.modify(WriteSet, key, func(old interface{}, ok bool) (interface{}, bool, error) {
+		return value, true, nil
+	})
 }
 
 func (c *defaultDataContext) Get(key string) (interface{}, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	v, ok := c.data[key]
-	return v, ok
+	return c.lookupLocked(key)
 }
 
 func (c *defaultDataContext) GetString(key string) (string, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	if v, ok := c.data[key]; ok {
+	if v, ok := c.Get(key); ok {
 		if s, ok2 := v.(string); ok2 {
 			return s, true
 		}
@@ -65,23 +122,333 @@ func (c *defaultDataContext) GetString(key string) (string, bool) {
 
 func (c *defaultDataContext) Delete(key string) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.sweepExpiredLocked()
+	old, existed := c.localLocked(key)
+	if !existed {
+		c.mu.Unlock()
+		return
+	}
 	delete(c.data, key)
+	delete(c.expires, key)
+	change := c.newChange(WriteDelete, key, nil, old, true)
+	change.Deleted = true
+	c.recordLocked(change)
+	c.mu.Unlock()
+
+	c.notify(change)
 }
 
 func (c *defaultDataContext) Has(key string) bool {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	_, ok := c.data[key]
+	_, ok := c.Get(key)
 	return ok
 }
 
+// Snapshot 返回当前可见的全部数据；子作用域中父作用域的数据被本地同名键覆盖
 func (c *defaultDataContext) Snapshot() map[string]interface{} {
+	merged := make(map[string]interface{})
+	if c.parent != nil {
+		merged = c.parent.Snapshot()
+	}
+	for k, v := range c.localSnapshot() {
+		merged[k] = v
+	}
+	return merged
+}
+
+// localSnapshot 返回本地存储的浅拷贝
+func (c *defaultDataContext) localSnapshot() map[string]interface{} {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	copy := make(map[string]interface{}, len(c.data))
 	for k, v := range c.data {
-		copy[k] = v
+		if !c.expiredLocked(k) {
+			copy[k] = v
+		}
 	}
 	return copy
-}
\ No newline at end of file
+}
+
+// hasLocal 判断键是否写在本地存储中
+func (c *defaultDataContext) hasLocal(key string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.localLocked(key)
+	return ok
+}
+
+// localLocked 在持有锁时读取本地存储中未过期的键
+func (c *defaultDataContext) localLocked(key string) (interface{}, bool) {
+	v, ok := c.data[key]
+	if !ok || c.expiredLocked(key) {
+		return nil, false
+	}
+	return v, true
+}
+
+// sweepExpiredLocked 删除本作用域中已过期的键，在每次写入时调用，
+// 使过期的值（如大的 []byte）不会一直占用内存。删除不产生写入历史与变更通知
+func (c *defaultDataContext) sweepExpiredLocked() {
+	if len(c.expires) == 0 {
+		return
+	}
+	now := time.Now()
+	for key, deadline := range c.expires {
+		if !now.Before(deadline) {
+			delete(c.data, key)
+			delete(c.expires, key)
+		}
+	}
+}
+
+// expiredLocked 判断键的 TTL 是否已过期
+func (c *defaultDataContext) expiredLocked(key string) bool {
+	deadline, ok := c.expires[key]
+	return ok && !time.Now().Before(deadline)
+}
+
+// lookupLocked 在持有锁时读取键，本地未命中时查询父作用域
+func (c *defaultDataContext) lookupLocked(key string) (interface{}, bool) {
+	if v, ok := c.localLocked(key); ok {
+		return v, true
+	}
+	if c.parent != nil {
+		return c.parent.Get(key)
+	}
+	return nil, false
+}
+
+// modify 是所有写操作的统一入口：在写锁内读取当前值并由 fn 决定是否写入，
+// 释放锁后通知观察者。fn 返回 write 为 false 时不写入也不通知
+func (c *defaultDataContext) modify(op WriteOp, key string, fn func(old interface{}, ok bool) (value interface{}, write bool, err error)) (interface{}, bool, error) {
+	return c.modifyTTL(op, key, 0, fn)
+}
+
+// modifyTTL 与 modify 相同，ttl 大于 0 时同时设置键的过期时间，否则清除之前的 TTL
+//
+// 需要写入 blob 存储的大值在锁外写入存储（磁盘 IO 不阻塞本作用域的读写），之后重新加锁并再次调用 fn：
+// 结果与已写入的内容相同时保存句柄，否则（键在此期间被修改）重复这一过程。因此 fn 可能被调用多次，
+// 未被使用的 blob 随存储一起删除
+func (c *defaultDataContext) modifyTTL(op WriteOp, key string, ttl time.Duration, fn func(old interface{}, ok bool) (value interface{}, write bool, err error)) (interface{}, bool, error) {
+	// 在加锁之前取得约束与 blob 存储，避免持有本作用域的锁时再获取父作用域的锁
+	schemas := c.dataSchemas()
+	blobs := c.blobStorage()
+
+	var spilled *spilledValue
+	for {
+		c.mu.Lock()
+		c.sweepExpiredLocked()
+		old, ok := c.lookupLocked(key)
+		value, write, err := fn(old, ok)
+		if err != nil || !write {
+			c.mu.Unlock()
+			return value, false, err
+		}
+		if blobs.exceeds(value) && !spilled.matches(value) {
+			c.mu.Unlock()
+			content := value.([]byte)
+			if schemas.rejects(key, content) {
+				// 会被拒绝的值不写入存储，重新加锁后按原值校验并记录违规
+				spilled = &spilledValue{content: content, stored: content}
+			} else {
+				spilled = blobs.spill(content)
+			}
+			continue
+		}
+		// 约束按写入存储之前的原值校验
+		if schemas != nil {
+			if violation, reject := schemas.check(key, c.path, value, c.writer); reject {
+				c.mu.Unlock()
+				return old, false, violation
+			}
+		}
+		if blobs.exceeds(value) {
+			value = spilled.stored
+		}
+		c.data[key] = value
+		if ttl > 0 {
+			if c.expires == nil {
+				c.expires = make(map[string]time.Time)
+			}
+			c.expires[key] = time.Now().Add(ttl)
+		} else {
+			delete(c.expires, key)
+		}
+		change := c.newChange(op, key, value, old, ok)
+		c.recordLocked(change)
+		c.mu.Unlock()
+
+		c.notify(change)
+		return value, true, nil
+	}
+}
+
+func (c *defaultDataContext) Update(key string, fn func(old interface{}, ok bool) interface{}) interface{} {
+	value, _, _ := c.modify(WriteUpdate, key, func(old interface{}, ok bool) (interface{}, bool, error) {
+		return fn(old, ok), true, nil
+	})
+	return value
+}
+
+func (c *defaultDataContext) CompareAndSwap(key string, old, new interface{}) bool {
+	_, swapped, _ := c.modify(WriteCompareAndSwap, key, func(current interface{}, ok bool) (interface{}, bool, error) {
+		return new, ok && reflect.DeepEqual(current, old), nil
+	})
+	return swapped
+}
+
+func (c *defaultDataContext) SetIfAbsent(key string, value interface{}) (interface{}, bool) {
+	return mustModify(c.modify(WriteSetIfAbsent, key, func(current interface{}, ok bool) (interface{}, bool, error) {
+		if ok {
+			return current, false, nil
+		}
+		return value, true, nil
+	}))
+}
+
+func (c *defaultDataContext) Incr(key string, delta int64) (int64, error) {
+	value, _, err := c.modify(WriteIncr, key, func(current interface{}, ok bool) (interface{}, bool, error) {
+		n, err := incrValue(key, current, delta)
+		return n, err == nil, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return value.(int64), nil
+}
+
+func (c *defaultDataContext) Append(key string, values ...interface{}) (int, error) {
+	list, _, err := c.modify(WriteAppend, key, func(current interface{}, ok bool) (interface{}, bool, error) {
+		list, err := appendValues(key, current, values)
+		return list, err == nil, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return reflect.ValueOf(list).Len(), nil
+}
+
+// mustModify 丢弃 modify 返回的错误（用于不会失败的写操作）
+func mustModify(value interface{}, written bool, _ error) (interface{}, bool) {
+	return value, written
+}
+
+// incrValue 计算计数器的新值，nil 视为 0
+func incrValue(key string, current interface{}, delta int64) (int64, error) {
+	if current == nil {
+		return delta, nil
+	}
+	n, ok := convertTo[int64](current)
+	if !ok {
+		return 0, &DataContextError{
+			Type:    "type_mismatch",
+			Key:     key,
+			Message: fmt.Sprintf("cannot increment value of type %T", current),
+		}
+	}
+	return n + delta, nil
+}
+
+// appendValues 向列表追加元素，nil 视为空的 []interface{}
+func appendValues(key string, current interface{}, values []interface{}) (interface{}, error) {
+	if current == nil {
+		list := make([]interface{}, 0, len(values))
+		return append(list, values...), nil
+	}
+	if list, ok := current.([]interface{}); ok {
+		// 复制后追加，避免修改调用方通过 Get/Snapshot 持有的切片
+		copied := make([]interface{}, len(list), len(list)+len(values))
+		copy(copied, list)
+		return append(copied, values...), nil
+	}
+
+	rv := reflect.ValueOf(current)
+	if rv.Kind() != reflect.Slice {
+		return nil, &DataContextError{
+			Type:    "type_mismatch",
+			Key:     key,
+			Message: fmt.Sprintf("cannot append to value of type %T", current),
+		}
+	}
+	elemType := rv.Type().Elem()
+	copied := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len()+len(values))
+	reflect.Copy(copied, rv)
+	for _, v := range values {
+		ev := reflect.ValueOf(v)
+		if v == nil {
+			switch elemType.Kind() {
+			case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+				copied = reflect.Append(copied, reflect.Zero(elemType))
+				continue
+			}
+		}
+		if v == nil || !ev.Type().AssignableTo(elemType) {
+			return nil, &DataContextError{
+				Type:    "type_mismatch",
+				Key:     key,
+				Message: fmt.Sprintf("cannot append %T to %T", v, current),
+			}
+		}
+		copied = reflect.Append(copied, ev)
+	}
+	return copied.Interface(), nil
+}
+
+func (c *defaultDataContext) Scope(name string) DataContext {
+	return c.bindScope(c.childScope(c.unbound(), "", name))
+}
+
+func (c *defaultDataContext) Export(keys ...string) error {
+	return &DataContextError{
+		Type:    "no_parent_scope",
+		Key:     strings.Join(keys, ","),
+		Message: "cannot export from the root data context",
+	}
+}
+
+// childScope 获取或创建 owner 下名为 name 的子作用域，c 为 owner 的本地存储
+func (c *defaultDataContext) childScope(owner DataContext, path, name string) *scopedDataContext {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if scope, ok := c.scopes[name]; ok {
+		return scope
+	}
+	if c.scopes == nil {
+		c.scopes = make(map[string]*scopedDataContext)
+	}
+	if path != "" {
+		path += "/"
+	}
+	scope := &scopedDataContext{
+		defaultDataContext: &defaultDataContext{dataStore: &dataStore{
+			data:    make(map[string]interface{}),
+			parent:  owner,
+			path:    path + name,
+			bubble:  c.notifyTree,
+			tracked: c.tracked,
+		}},
+		name: name,
+	}
+	c.scopes[name] = scope
+	return scope
+}
+
+func (c *defaultDataContext) scopeSnapshot() *ScopeSnapshot {
+	return c.snapshotTree("", c.localSnapshot())
+}
+
+// snapshotTree 生成本作用域及其子作用域的快照
+func (c *defaultDataContext) snapshotTree(name string, data map[string]interface{}) *ScopeSnapshot {
+	c.mu.RLock()
+	children := make([]*scopedDataContext, 0, len(c.scopes))
+	for _, scope := range c.scopes {
+		children = append(children, scope)
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(children, func(i, j int) bool { return children[i].name < children[j].name })
+	snapshot := &ScopeSnapshot{Name: name, Path: c.path, Data: data}
+	for _, child := range children {
+		snapshot.Scopes = append(snapshot.Scopes, child.scopeSnapshot())
+	}
+	return snapshot
+}
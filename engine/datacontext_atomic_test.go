@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestDataContextAtomicOperations(t *testing.T) {
+	t.Run("Update", func(t *testing.T) {
+		data := NewDataContext()
+		got := data.Update("count", func(old interface{}, ok bool) interface{} {
+			if ok {
+				t.Error("Expected key to be absent")
+			}
+			return 1
+		})
+		if got != 1 {
+			t.Errorf("Expected 1, got %v", got)
+		}
+		data.Update("count", func(old interface{}, ok bool) interface{} { return old.(int) + 1 })
+		if v, _ := data.Get("count"); v != 2 {
+			t.Errorf("Expected 2, got %v", v)
+		}
+	})
+
+	t.Run("CompareAndSwap", func(t *testing.T) {
+		data := NewDataContextWith(map[string]interface{}{"state": "pending", "tags": []string{"a"}})
+		if data.CompareAndSwap("missing", nil, "x") {
+			t.Error("Expected swap on missing key to fail")
+		}
+		if data.CompareAndSwap("state", "running", "done") {
+			t.Error("Expected swap with wrong old value to fail")
+		}
+		if !data.CompareAndSwap("state", "pending", "running") {
+			t.Error("Expected swap to succeed")
+		}
+		if !data.CompareAndSwap("tags", []string{"a"}, []string{"b"}) {
+			t.Error("Expected swap of non-comparable value to succeed")
+		}
+	})
+
+	t.Run("SetIfAbsent", func(t *testing.T) {
+		data := NewDataContext()
+		if v, stored := data.SetIfAbsent("owner", "a"); !stored || v != "a" {
+			t.Errorf("Expected first write to be stored, got %v %v", v, stored)
+		}
+		if v, stored := data.SetIfAbsent("owner", "b"); stored || v != "a" {
+			t.Errorf("Expected existing value a, got %v %v", v, stored)
+		}
+	})
+
+	t.Run("Incr", func(t *testing.T) {
+		data := NewDataContextWith(map[string]interface{}{"from_json": float64(2), "name": "x"})
+		if n, err := data.Incr("hits", 1); err != nil || n != 1 {
+			t.Errorf("Expected 1, got %v %v", n, err)
+		}
+		if n, err := data.Incr("from_json", 3); err != nil || n != 5 {
+			t.Errorf("Expected 5, got %v %v", n, err)
+		}
+		var dcErr *DataContextError
+		if _, err := data.Incr("name", 1); !errors.As(err, &dcErr) || dcErr.Key != "name" {
+			t.Errorf("Expected DataContextError, got %v", err)
+		}
+	})
+
+	t.Run("Append", func(t *testing.T) {
+		data := NewDataContextWith(map[string]interface{}{"names": []string{"a"}, "count": 1})
+		if n, err := data.Append("errors", "e1", "e2"); err != nil || n != 2 {
+			t.Errorf("Expected 2, got %v %v", n, err)
+		}
+		before, _ := data.Get("errors")
+		data.Append("errors", "e3")
+		if len(before.([]interface{})) != 2 {
+			t.Error("Append must not modify previously returned slices")
+		}
+		if n, err := data.Append("names", "b"); err != nil || n != 2 {
+			t.Errorf("Expected typed append to succeed, got %v %v", n, err)
+		}
+		if names, _ := data.Get("names"); names.([]string)[1] != "b" {
+			t.Errorf("Unexpected names: %v", names)
+		}
+		if _, err := data.Append("names", 1); err == nil {
+			t.Error("Expected element type mismatch")
+		}
+		if _, err := data.Append("count", 1); err == nil {
+			t.Error("Expected append to non-slice to fail")
+		}
+	})
+}
+
+func TestDataContextAtomicConcurrency(t *testing.T) {
+	data := NewDataContext()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data.Incr("counter", 1)
+			data.Append("errors", i)
+			data.Update("sum", func(old interface{}, ok bool) interface{} {
+				if !ok {
+					return i
+				}
+				return old.(int) + i
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	if n, _ := GetInt(data, "counter"); n != 50 {
+		t.Errorf("Expected counter 50, got %d", n)
+	}
+	if list, _ := Get[[]interface{}](data, "errors"); len(list) != 50 {
+		t.Errorf("Expected 50 appended errors, got %d", len(list))
+	}
+	if sum, _ := GetInt(data, "sum"); sum != 49*50/2 {
+		t.Errorf("Expected sum %d, got %d", 49*50/2, sum)
+	}
+}
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cleanupStack 按登记顺序保存的清理函数，整棵作用域树共用根上下文中的一个
+type cleanupStack struct {
+	mu      sync.Mutex
+	entries []cleanupEntry
+}
+
+// cleanupEntry 一个登记的清理函数及登记它的键与组件
+type cleanupEntry struct {
+	key       string
+	scope     string
+	component string
+	fn        func()
+}
+
+func (s *cleanupStack) push(entry cleanupEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+}
+
+// drain 取出所有清理函数，之后登记的清理函数在下一次 RunCleanups 时执行
+func (s *cleanupStack) drain() []cleanupEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := s.entries
+	s.entries = nil
+	return entries
+}
+
+// RunCleanups 按登记的逆序调用 data 中通过 SetWithCleanup 登记的清理函数，每个函数只调用一次
+// 清理函数 panic 时继续执行其余的清理函数，最后返回汇总的 DataContextError
+// 引擎在 Execute 结束时自动调用，独立使用 DataContext 时由调用方负责调用
+// 引擎不等待 AsyncMode 层的组件结束：Execute 返回时仍在运行的异步组件可能在清理之后继续使用相关的值，
+// 之后登记的清理函数留到下一次 RunCleanups。需要可靠释放资源的组件不应放在异步层中
+func RunCleanups(data DataContext) error {
+	holder, ok := data.(interface{ cleanupStack() *cleanupStack })
+	if !ok {
+		return nil
+	}
+	entries := holder.cleanupStack().drain()
+
+	var failures []string
+	for i := len(entries) - 1; i >= 0; i-- {
+		if err := entries[i].run(); err != "" {
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return &DataContextError{
+		Type:    "cleanup_panicked",
+		Message: fmt.Sprintf("%d cleanup(s) panicked: %s", len(failures), strings.Join(failures, "; ")),
+	}
+}
+
+// run 调用清理函数，panic 时返回描述
+func (e cleanupEntry) run() (failure string) {
+	defer func() {
+		if r := recover(); r != nil {
+			key := e.key
+			if e.scope != "" {
+				key = e.scope + "/" + e.key
+			}
+			failure = fmt.Sprintf("key %s: %v", key, r)
+			if e.component != "" {
+				failure = fmt.Sprintf("key %s (component %s): %v", key, e.component, r)
+			}
+		}
+	}()
+	e.fn()
+	return ""
+}
+
+func (c *defaultDataContext) SetWithCleanup(key string, value interface{}, cleanup func()) {
+	c.Set(key, value)
+	c.addCleanup(key, cleanup)
+}
+
+func (c *defaultDataContext) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	c.modifyTTL(WriteSet, key, ttl, func(old interface{}, ok bool) (interface{}, bool, error) {
+		return value, true, nil
+	})
+}
+
+// addCleanup 将清理函数登记到根上下文
+func (c *defaultDataContext) addCleanup(key string, cleanup func()) {
+	if cleanup == nil {
+		return
+	}
+	entry := cleanupEntry{key: key, scope: c.path, fn: cleanup}
+	if c.writer != nil {
+		entry.component = c.writer.Component
+	}
+	c.cleanupStack().push(entry)
+}
+
+// cleanupStack 返回根上下文的清理函数栈，作用域委托给父上下文
+func (c *defaultDataContext) cleanupStack() *cleanupStack {
+	if holder, ok := c.parent.(interface{ cleanupStack() *cleanupStack }); ok {
+		return holder.cleanupStack()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cleanups == nil {
+		c.cleanups = &cleanupStack{}
+	}
+	return c.cleanups
+}
@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDataContextTTL(t *testing.T) {
+	data := NewDataContext()
+	data.SetWithTTL("token", "abc", 20*time.Millisecond)
+	data.SetWithTTL("renewed", 1, 20*time.Millisecond)
+	scope := data.Scope("s")
+
+	if v, ok := scope.GetString("token"); !ok || v != "abc" {
+		t.Fatalf("Expected key to be visible before expiry, got %q %v", v, ok)
+	}
+	data.Set("renewed", 2)
+
+	time.Sleep(30 * time.Millisecond)
+	if data.Has("token") || scope.Has("token") {
+		t.Error("Expected key to expire")
+	}
+	if _, ok := data.Snapshot()["token"]; ok {
+		t.Error("Expected expired key to be excluded from snapshots")
+	}
+	if v, ok := data.Get("renewed"); !ok || v != 2 {
+		t.Errorf("Expected a later Set to clear the TTL, got %v %v", v, ok)
+	}
+	if _, ok := data.SetIfAbsent("token", "new"); !ok {
+		t.Error("Expected an expired key to be treated as absent")
+	}
+
+	// 过期的键在下一次写入时从存储中删除
+	data.SetWithTTL("large", make([]byte, 1024), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	data.Set("other", 1)
+	store := data.(*defaultDataContext).dataStore
+	store.mu.RLock()
+	_, held := store.data["large"]
+	_, tracked := store.expires["large"]
+	store.mu.RUnlock()
+	if held || tracked {
+		t.Error("Expected expired keys to be swept on write")
+	}
+}
+
+func TestRunCleanups(t *testing.T) {
+	data := NewDataContext()
+	var order []string
+	data.SetWithCleanup("a", 1, func() { order = append(order, "a") })
+	data.Scope("s").SetWithCleanup("b", 2, func() { panic("boom") })
+	data.SetWithCleanup("c", 3, func() { order = append(order, "c") })
+	data.Delete("c")
+
+	var dcErr *DataContextError
+	if err := RunCleanups(data); !errors.As(err, &dcErr) || dcErr.Type != "cleanup_panicked" {
+		t.Fatalf("Expected cleanup_panicked, got %v", err)
+	}
+	if !reflect.DeepEqual(order, []string{"c", "a"}) {
+		t.Errorf("Expected cleanups in reverse order despite the panic, got %v", order)
+	}
+	if err := RunCleanups(data); err != nil || len(order) != 2 {
+		t.Errorf("Expected each cleanup to run once, got %v %v", order, err)
+	}
+}
+
+func TestEngineCleanupsOnFailure(t *testing.T) {
+	config := &Config{
+		Name: "cleanup",
+		Layers: []LayerConfig{
+			{Name: "open", Mode: ParallelMode, Components: []ComponentConfig{
+				{Name: "first", Type: "opener"},
+				{Name: "second", Type: "opener"},
+			}},
+			{Name: "use", Mode: SerialMode, Components: []ComponentConfig{{Name: "fail", Type: "fail"}}},
+		},
+	}
+
+	var closed []string
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "opener",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				data.SetWithCleanup(config.Name+"_handle", config.Name, func() { closed = append(closed, config.Name) })
+				return errors.New("attempt failed")
+			}}, nil
+		},
+	})
+	registry.Register(&MockComponentFactory{
+		componentType: "fail",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				return errors.New("fail")
+			}}, nil
+		},
+	})
+
+	// 事务模式下失败尝试的写入被丢弃，但登记的清理函数仍然执行
+	engine, err := NewEngine(config, registry, WithTransactionalData(), WithLogger(&MockLogger{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data := NewDataContext()
+	if _, err := engine.Execute(context.Background(), data); err == nil {
+		t.Fatal("Expected execution error")
+	}
+	if len(closed) != 2 {
+		t.Errorf("Expected both cleanups to run after the failed run, got %v", closed)
+	}
+	if data.Has("first_handle") {
+		t.Error("Expected the failed attempt's write to be discarded")
+	}
+}
+
+func TestTxDataContextTTL(t *testing.T) {
+	parent := NewDataContextWith(map[string]interface{}{"gone": 1})
+	tx := newTxDataContext(parent, ExecutionInfo{Component: "c"}, nil)
+	tx.SetWithTTL("short", "x", time.Millisecond)
+	tx.SetWithTTL("gone", 2, time.Millisecond)
+	tx.SetWithTTL("long", "y", time.Hour)
+
+	time.Sleep(5 * time.Millisecond)
+	if tx.Has("short") || tx.Has("gone") || !tx.Has("long") {
+		t.Errorf("Expected tx reads to honor TTL, got %v", tx.Snapshot())
+	}
+	if err := tx.commit(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := map[string]interface{}{"long": "y"}; !reflect.DeepEqual(parent.Snapshot(), want) {
+		t.Errorf("Unexpected parent after commit: %v", parent.Snapshot())
+	}
+}
@@ -0,0 +1,384 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DataCodec 序列化 DataContext 时使用的编码
+// 每个值单独编码后与键、类型名一起组成文档，文档本身也使用同一编码
+type DataCodec interface {
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	// JSONCodec 可读的 JSON 编码，适合调试输出与测试夹具
+	JSONCodec DataCodec = jsonCodec{}
+	// GobCodec 紧凑的 gob 编码，适合检查点；值中嵌套的 interface{} 需要通过 gob.Register 注册
+	GobCodec DataCodec = gobCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                               { return "json" }
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string { return "gob" }
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// DataTypeRegistry 类型名与 Go 类型的映射，反序列化时据此还原每个值的具体类型
+type DataTypeRegistry struct {
+	mu     sync.RWMutex
+	byName map[string]reflect.Type
+	byType map[reflect.Type]string
+}
+
+// NewDataTypeRegistry 创建预先注册了基本类型的类型注册表
+// 基本类型包括字符串、布尔、各类整数与浮点数、[]byte、[]string、[]interface{}、
+// map[string]interface{}、map[string]string、time.Duration、time.Time 以及 blob 句柄 *Blob
+func NewDataTypeRegistry() *DataTypeRegistry {
+	r := &DataTypeRegistry{
+		byName: make(map[string]reflect.Type),
+		byType: make(map[reflect.Type]string),
+	}
+	builtins := []struct {
+		name   string
+		sample interface{}
+	}{
+		{"string", ""}, {"bool", false},
+		{"int", int(0)}, {"int8", int8(0)}, {"int16", int16(0)}, {"int32", int32(0)}, {"int64", int64(0)},
+		{"uint", uint(0)}, {"uint8", uint8(0)}, {"uint16", uint16(0)}, {"uint32", uint32(0)}, {"uint64", uint64(0)},
+		{"float32", float32(0)}, {"float64", float64(0)},
+		{"[]byte", []byte(nil)}, {"[]string", []string(nil)}, {"[]interface{}", []interface{}(nil)},
+		{"map[string]interface{}", map[string]interface{}(nil)}, {"map[string]string", map[string]string(nil)},
+		{"time.Duration", time.Duration(0)}, {"time.Time", time.Time{}},
+		{"blob", &Blob{}},
+	}
+	for _, builtin := range builtins {
+		r.Register(builtin.name, builtin.sample)
+	}
+	return r
+}
+
+// DefaultDataTypes 默认的类型注册表，MarshalJSON/GobEncode 等未指定注册表的序列化使用它
+var DefaultDataTypes = NewDataTypeRegistry()
+
+// RegisterDataType 在 DefaultDataTypes 中注册类型，通常在 init 中调用
+func RegisterDataType(name string, sample interface{}) error {
+	return DefaultDataTypes.Register(name, sample)
+}
+
+// Register 以 name 注册 sample 的类型（指针类型与其元素类型需分别注册）
+// 同名或同类型已以不同的对应关系注册时返回 DataContextError
+func (r *DataTypeRegistry) Register(name string, sample interface{}) error {
+	if name == "" || sample == nil {
+		return &DataContextError{Type: "invalid_type", Key: name, Message: "type name and sample cannot be empty"}
+	}
+	t := reflect.TypeOf(sample)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.byName[name]; ok && existing != t {
+		return &DataContextError{Type: "duplicate_type", Key: name, Message: fmt.Sprintf("type name already registered for %s", existing)}
+	}
+	if existing, ok := r.byType[t]; ok && existing != name {
+		return &DataContextError{Type: "duplicate_type", Key: name, Message: fmt.Sprintf("type %s already registered as %s", t, existing)}
+	}
+	r.byName[name] = t
+	r.byType[t] = name
+	return nil
+}
+
+// Lookup 返回类型名对应的 Go 类型
+func (r *DataTypeRegistry) Lookup(name string) (reflect.Type, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.byName[name]
+	return t, ok
+}
+
+// nameOf 返回 Go 类型的注册名
+func (r *DataTypeRegistry) nameOf(t reflect.Type) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok := r.byType[t]
+	return name, ok
+}
+
+// SerializeOption 序列化选项
+type SerializeOption func(*serializeOptions)
+
+type serializeOptions struct {
+	codec DataCodec
+	types *DataTypeRegistry
+}
+
+// WithDataCodec 指定编码，默认为 JSONCodec
+func WithDataCodec(codec DataCodec) SerializeOption {
+	return func(o *serializeOptions) {
+		o.codec = codec
+	}
+}
+
+// WithDataTypes 指定类型注册表，默认为 DefaultDataTypes
+func WithDataTypes(types *DataTypeRegistry) SerializeOption {
+	return func(o *serializeOptions) {
+		o.types = types
+	}
+}
+
+func newSerializeOptions(options []SerializeOption) *serializeOptions {
+	o := &serializeOptions{codec: JSONCodec, types: DefaultDataTypes}
+	for _, option := range options {
+		option(o)
+	}
+	return o
+}
+
+// dataDocument 序列化后的 DataContext，条目按键排序
+type dataDocument struct {
+	Codec   string      `json:"codec"`
+	Entries []dataEntry `json:"entries"`
+}
+
+// dataEntry 一个键值；Value 为按文档编码后的值，JSON 编码时原样嵌入文档
+type dataEntry struct {
+	Key   string          `json:"key"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// nilTypeName 值为 nil 的键使用的类型名
+const nilTypeName = "nil"
+
+// MarshalDataContext 序列化 data 当前可见的全部数据（作用域为合并了父作用域的视图）
+// 值的类型必须已在类型注册表中注册，否则返回 DataContextError（unregistered_type）
+func MarshalDataContext(data DataContext, options ...SerializeOption) ([]byte, error) {
+	return marshalValues(data.Snapshot(), newSerializeOptions(options))
+}
+
+// UnmarshalDataContext 将序列化的数据逐个 Set 到 data 中，已有的同名键被覆盖
+func UnmarshalDataContext(b []byte, data DataContext, options ...SerializeOption) error {
+	values, err := unmarshalValues(b, newSerializeOptions(options))
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		data.Set(key, values[key])
+	}
+	return nil
+}
+
+// RestoreDataContext 从 MarshalDataContext 的输出创建新的数据上下文
+func RestoreDataContext(b []byte, options ...SerializeOption) (DataContext, error) {
+	values, err := unmarshalValues(b, newSerializeOptions(options))
+	if err != nil {
+		return nil, err
+	}
+	return NewDataContextWith(values), nil
+}
+
+func marshalValues(values map[string]interface{}, o *serializeOptions) ([]byte, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	doc := dataDocument{Codec: o.codec.Name(), Entries: make([]dataEntry, 0, len(keys))}
+	for _, key := range keys {
+		value := values[key]
+		if value == nil {
+			doc.Entries = append(doc.Entries, dataEntry{Key: key, Type: nilTypeName})
+			continue
+		}
+		name, ok := o.types.nameOf(reflect.TypeOf(value))
+		if !ok {
+			return nil, &DataContextError{
+				Type:    "unregistered_type",
+				Key:     key,
+				Message: fmt.Sprintf("type %T is not registered, see RegisterDataType", value),
+			}
+		}
+		encoded, err := o.codec.Marshal(value)
+		if err != nil {
+			return nil, &DataContextError{Type: "encode_failed", Key: key, Message: err.Error(), Cause: err}
+		}
+		doc.Entries = append(doc.Entries, dataEntry{Key: key, Type: name, Value: encoded})
+	}
+
+	b, err := o.codec.Marshal(doc)
+	if err != nil {
+		return nil, &DataContextError{Type: "encode_failed", Message: err.Error(), Cause: err}
+	}
+	return b, nil
+}
+
+func unmarshalValues(b []byte, o *serializeOptions) (map[string]interface{}, error) {
+	var doc dataDocument
+	if err := o.codec.Unmarshal(b, &doc); err != nil {
+		return nil, &DataContextError{Type: "decode_failed", Message: err.Error(), Cause: err}
+	}
+	if doc.Codec != o.codec.Name() {
+		return nil, &DataContextError{
+			Type:    "codec_mismatch",
+			Message: fmt.Sprintf("data was encoded with codec %q, decoding with %q", doc.Codec, o.codec.Name()),
+		}
+	}
+
+	values := make(map[string]interface{}, len(doc.Entries))
+	for _, entry := range doc.Entries {
+		if entry.Type == nilTypeName {
+			values[entry.Key] = nil
+			continue
+		}
+		t, ok := o.types.Lookup(entry.Type)
+		if !ok {
+			return nil, &DataContextError{
+				Type:    "unknown_type",
+				Key:     entry.Key,
+				Message: fmt.Sprintf("type %s is not registered, see RegisterDataType", entry.Type),
+			}
+		}
+		target := reflect.New(t)
+		if err := o.codec.Unmarshal(entry.Value, target.Interface()); err != nil {
+			return nil, &DataContextError{Type: "decode_failed", Key: entry.Key, Message: err.Error(), Cause: err}
+		}
+		values[entry.Key] = target.Elem().Interface()
+	}
+	return values, nil
+}
+
+// MarshalJSON 使用 JSONCodec 与 DefaultDataTypes 序列化当前可见的数据
+func (c *defaultDataContext) MarshalJSON() ([]byte, error) {
+	return MarshalDataContext(c, WithDataCodec(JSONCodec))
+}
+
+// UnmarshalJSON 使用 JSONCodec 与 DefaultDataTypes 写入数据
+func (c *defaultDataContext) UnmarshalJSON(b []byte) error {
+	c.ensureStore()
+	return UnmarshalDataContext(b, c, WithDataCodec(JSONCodec))
+}
+
+// GobEncode 使用 GobCodec 与 DefaultDataTypes 序列化当前可见的数据
+func (c *defaultDataContext) GobEncode() ([]byte, error) {
+	return MarshalDataContext(c, WithDataCodec(GobCodec))
+}
+
+// GobDecode 使用 GobCodec 与 DefaultDataTypes 写入数据
+func (c *defaultDataContext) GobDecode(b []byte) error {
+	c.ensureStore()
+	return UnmarshalDataContext(b, c, WithDataCodec(GobCodec))
+}
+
+// ensureStore 零值的 defaultDataContext（如解码目标）初始化存储
+func (c *defaultDataContext) ensureStore() {
+	if c.dataStore == nil {
+		c.dataStore = &dataStore{data: make(map[string]interface{})}
+	}
+}
+
+// DeepSnapshot 返回 data 当前可见数据的深拷贝：map、slice、数组、指针与结构体的导出字段被递归复制，
+// 之后修改原数据中的引用类型不会影响快照；结构体的未导出字段、chan、func 仍与原值共享
+func DeepSnapshot(data DataContext) map[string]interface{} {
+	snapshot := data.Snapshot()
+	copier := &deepCopier{seen: make(map[uintptr]reflect.Value)}
+	for key, value := range snapshot {
+		if value == nil {
+			continue
+		}
+		snapshot[key] = copier.copy(reflect.ValueOf(value)).Interface()
+	}
+	return snapshot
+}
+
+// deepCopier 递归复制值，seen 记录已复制的指针以保留共享与环
+type deepCopier struct {
+	seen map[uintptr]reflect.Value
+}
+
+func (d *deepCopier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if copied, ok := d.seen[v.Pointer()]; ok && copied.Type() == v.Type() {
+			return copied
+		}
+		copied := reflect.New(v.Type().Elem())
+		d.seen[v.Pointer()] = copied
+		copied.Elem().Set(d.copy(v.Elem()))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(d.copy(v.Elem()))
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), d.copy(iter.Value()))
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(d.copy(v.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(d.copy(v.Index(i)))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := copied.Field(i); field.CanSet() {
+				field.Set(d.copy(v.Field(i)))
+			}
+		}
+		return copied
+	default:
+		return v
+	}
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type codecTestUser struct {
+	ID    int
+	Name  string
+	Tags  []string
+	Extra map[string]string
+}
+
+func newCodecTestTypes(t *testing.T) *DataTypeRegistry {
+	types := NewDataTypeRegistry()
+	if err := types.Register("user", codecTestUser{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := types.Register("*user", &codecTestUser{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return types
+}
+
+func TestMarshalDataContextRoundTrip(t *testing.T) {
+	types := newCodecTestTypes(t)
+	original := map[string]interface{}{
+		"count":    42,
+		"ratio":    0.5,
+		"name":     "kflow",
+		"raw":      []byte("hello"),
+		"timeout":  5 * time.Second,
+		"user":     codecTestUser{ID: 1, Name: "a", Tags: []string{"x"}},
+		"user_ptr": &codecTestUser{ID: 2, Extra: map[string]string{"k": "v"}},
+		"nothing":  nil,
+		"list":     []interface{}{"a", true},
+	}
+
+	for _, codec := range []DataCodec{JSONCodec, GobCodec} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			b, err := MarshalDataContext(NewDataContextWith(original), WithDataCodec(codec), WithDataTypes(types))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			restored, err := RestoreDataContext(b, WithDataCodec(codec), WithDataTypes(types))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := restored.Snapshot(); !reflect.DeepEqual(got, original) {
+				t.Errorf("Round trip mismatch:\n got %#v\nwant %#v", got, original)
+			}
+		})
+	}
+}
+
+func TestMarshalDataContextErrors(t *testing.T) {
+	var dcErr *DataContextError
+
+	_, err := MarshalDataContext(NewDataContextWith(map[string]interface{}{"user": codecTestUser{}}))
+	if !errors.As(err, &dcErr) || dcErr.Type != "unregistered_type" || dcErr.Key != "user" {
+		t.Errorf("Expected unregistered_type for user, got %v", err)
+	}
+
+	types := newCodecTestTypes(t)
+	b, err := MarshalDataContext(NewDataContextWith(map[string]interface{}{"user": codecTestUser{}}), WithDataTypes(types))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := RestoreDataContext(b); !errors.As(err, &dcErr) || dcErr.Type != "unknown_type" {
+		t.Errorf("Expected unknown_type without the registry, got %v", err)
+	}
+	if _, err := RestoreDataContext(b, WithDataCodec(GobCodec), WithDataTypes(types)); !errors.As(err, &dcErr) || dcErr.Type != "decode_failed" {
+		t.Errorf("Expected decode_failed for a codec mismatch, got %v", err)
+	}
+
+	if err := types.Register("user", 1); !errors.As(err, &dcErr) || dcErr.Type != "duplicate_type" {
+		t.Errorf("Expected duplicate_type for a reused name, got %v", err)
+	}
+	if err := types.Register("person", codecTestUser{}); !errors.As(err, &dcErr) || dcErr.Type != "duplicate_type" {
+		t.Errorf("Expected duplicate_type for a reused type, got %v", err)
+	}
+	if err := types.Register("user", codecTestUser{}); err != nil {
+		t.Errorf("Expected re-registering the same mapping to succeed, got %v", err)
+	}
+}
+
+func TestDataContextJSONMarshaler(t *testing.T) {
+	data := NewDataContextWith(map[string]interface{}{"name": "kflow", "count": int64(3)})
+	b, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(string(b), `{"key":"count","type":"int64","value":3}`) {
+		t.Errorf("Expected readable JSON entries, got %s", b)
+	}
+
+	restored := NewDataContextWith(map[string]interface{}{"existing": true})
+	if err := json.Unmarshal(b, restored); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n, ok := restored.Get("count"); !ok || n != int64(3) || !restored.Has("existing") {
+		t.Errorf("Unexpected restored data: %v", restored.Snapshot())
+	}
+}
+
+func TestDeepSnapshot(t *testing.T) {
+	user := &codecTestUser{ID: 1, Tags: []string{"a"}}
+	nested := map[string]interface{}{"list": []interface{}{1}}
+	data := NewDataContextWith(map[string]interface{}{"user": user, "nested": nested, "again": user})
+
+	snapshot := DeepSnapshot(data)
+	user.Tags[0] = "changed"
+	user.ID = 2
+	nested["list"].([]interface{})[0] = 2
+
+	copied := snapshot["user"].(*codecTestUser)
+	if copied == user || copied.ID != 1 || copied.Tags[0] != "a" {
+		t.Errorf("Expected an independent copy, got %+v", copied)
+	}
+	if snapshot["nested"].(map[string]interface{})["list"].([]interface{})[0] != 1 {
+		t.Errorf("Expected nested values to be copied, got %v", snapshot["nested"])
+	}
+	if snapshot["again"] != snapshot["user"] {
+		t.Error("Expected shared pointers to stay shared within one snapshot")
+	}
+}
@@ -0,0 +1,196 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// WriteOp 写入 DataContext 的操作类型
+type WriteOp string
+
+const (
+	WriteSet            WriteOp = "set"
+	WriteDelete         WriteOp = "delete"
+	WriteUpdate         WriteOp = "update"
+	WriteCompareAndSwap WriteOp = "compare_and_swap"
+	WriteSetIfAbsent    WriteOp = "set_if_absent"
+	WriteIncr           WriteOp = "incr"
+	WriteAppend         WriteOp = "append"
+)
+
+// ExecutionInfo 标识当前正在执行的组件，引擎在每次组件执行（包括重试）时写入 context
+type ExecutionInfo struct {
+	Layer     string `json:"layer"`
+	Component string `json:"component"`
+	Attempt   int    `json:"attempt"` // 从 1 开始，重试时递增
+}
+
+type executionInfoKey struct{}
+
+// ContextWithExecutionInfo 返回携带 info 的 context
+func ContextWithExecutionInfo(ctx context.Context, info ExecutionInfo) context.Context {
+	return context.WithValue(ctx, executionInfoKey{}, info)
+}
+
+// ExecutionInfoFromContext 读取引擎写入 context 的组件执行信息
+func ExecutionInfoFromContext(ctx context.Context) (ExecutionInfo, bool) {
+	info, ok := ctx.Value(executionInfoKey{}).(ExecutionInfo)
+	return info, ok
+}
+
+// WriteRecord 一次写入的记录
+type WriteRecord struct {
+	Op        WriteOp     `json:"op"`
+	Value     interface{} `json:"value,omitempty"` // 写入的值，删除时为 nil
+	Layer     string      `json:"layer,omitempty"`
+	Component string      `json:"component,omitempty"` // 引擎之外的写入为空
+	Attempt   int         `json:"attempt,omitempty"`
+	Scope     string      `json:"scope,omitempty"`
+	Time      time.Time   `json:"time"`
+}
+
+// TrackedDataContext 记录每个键写入历史的 DataContext
+type TrackedDataContext interface {
+	DataContext
+
+	// History 返回 key 在本作用域中的写入历史，按写入顺序排列
+	History(key string) []WriteRecord
+}
+
+// NewTrackedDataContext 创建记录写入历史的数据上下文，其子作用域同样记录历史
+// 由引擎执行的组件写入时记录组件、层与重试次数（见 ExecutionInfo），
+// Execute 结束后整棵作用域树的历史写入 ExecutionStats.DataHistory
+func NewTrackedDataContext() TrackedDataContext {
+	return NewTrackedDataContextWith(nil)
+}
+
+// NewTrackedDataContextWith 使用初始数据创建记录写入历史的数据上下文，初始数据不计入历史
+func NewTrackedDataContextWith(initial map[string]interface{}) TrackedDataContext {
+	ctx := NewDataContextWith(initial).(*defaultDataContext)
+	ctx.tracked = true
+	ctx.history = make(map[string][]WriteRecord)
+	return ctx
+}
+
+// DataHistory 返回 data 及其所有子作用域中每个键的写入历史，同一键在不同作用域的写入按时间合并
+// data 未记录历史时返回 nil
+func DataHistory(data DataContext) map[string][]WriteRecord {
+	node, ok := data.(interface {
+		collectHistory(into map[string][]WriteRecord)
+	})
+	if !ok {
+		return nil
+	}
+	history := make(map[string][]WriteRecord)
+	node.collectHistory(history)
+	if len(history) == 0 {
+		return nil
+	}
+	for _, records := range history {
+		sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	}
+	return history
+}
+
+func (c *defaultDataContext) History(key string) []WriteRecord {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]WriteRecord(nil), c.history[key]...)
+}
+
+// collectHistory 将本作用域及其子作用域的历史追加到 into
+func (c *defaultDataContext) collectHistory(into map[string][]WriteRecord) {
+	c.mu.RLock()
+	for key, records := range c.history {
+		into[key] = append(into[key], records...)
+	}
+	children := make([]*scopedDataContext, 0, len(c.scopes))
+	for _, scope := range c.scopes {
+		children = append(children, scope)
+	}
+	c.mu.RUnlock()
+
+	for _, child := range children {
+		child.collectHistory(into)
+	}
+}
+
+// newChange 构造一次写入的变更，写入者取自绑定的 ExecutionInfo
+func (c *defaultDataContext) newChange(op WriteOp, key string, value, old interface{}, existed bool) Change {
+	change := Change{
+		Key:     key,
+		Op:      op,
+		Value:   value,
+		Old:     old,
+		Existed: existed,
+		Scope:   c.path,
+		Time:    time.Now(),
+	}
+	if c.writer != nil {
+		change.Layer = c.writer.Layer
+		change.Component = c.writer.Component
+		change.Attempt = c.writer.Attempt
+	}
+	return change
+}
+
+// recordLocked 在持有写锁时记录写入历史，保证同一作用域内记录顺序与写入顺序一致
+func (c *defaultDataContext) recordLocked(change Change) {
+	if !c.tracked {
+		return
+	}
+	if c.history == nil {
+		c.history = make(map[string][]WriteRecord)
+	}
+	c.history[change.Key] = append(c.history[change.Key], WriteRecord{
+		Op:        change.Op,
+		Value:     change.Value,
+		Layer:     change.Layer,
+		Component: change.Component,
+		Attempt:   change.Attempt,
+		Scope:     change.Scope,
+		Time:      change.Time,
+	})
+}
+
+// bindWriter 返回将写入归属于 info 的 data 视图，不支持的实现原样返回
+func bindWriter(data DataContext, info ExecutionInfo) DataContext {
+	if binder, ok := data.(interface {
+		bind(info ExecutionInfo) DataContext
+	}); ok {
+		return binder.bind(info)
+	}
+	return data
+}
+
+func (c *defaultDataContext) bind(info ExecutionInfo) DataContext {
+	return &defaultDataContext{dataStore: c.dataStore, writer: &info}
+}
+
+func (s *scopedDataContext) bind(info ExecutionInfo) DataContext {
+	return &scopedDataContext{defaultDataContext: &defaultDataContext{dataStore: s.dataStore, writer: &info}, name: s.name}
+}
+
+// unbound 返回不带写入者的视图，用作子作用域的父作用域（子作用域会被缓存并被不同写入者共享）
+func (c *defaultDataContext) unbound() DataContext {
+	if c.writer == nil {
+		return c
+	}
+	return &defaultDataContext{dataStore: c.dataStore}
+}
+
+func (s *scopedDataContext) unbound() DataContext {
+	if s.writer == nil {
+		return s
+	}
+	return &scopedDataContext{defaultDataContext: &defaultDataContext{dataStore: s.dataStore}, name: s.name}
+}
+
+// bindScope 子作用域继承当前视图的写入者
+func (c *defaultDataContext) bindScope(scope *scopedDataContext) DataContext {
+	if c.writer == nil {
+		return scope
+	}
+	return scope.bind(*c.writer)
+}
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestTrackedDataContextHistory(t *testing.T) {
+	data := NewTrackedDataContextWith(map[string]interface{}{"initial": 1})
+
+	data.Set("key", "a")
+	data.Incr("count", 2)
+	data.CompareAndSwap("key", "other", "b") // 未写入，不记录
+	data.Delete("key")
+	data.Delete("missing") // 键不存在，不记录
+
+	if history := data.History("initial"); len(history) != 0 {
+		t.Errorf("Expected initial data not to be recorded, got %+v", history)
+	}
+	history := data.History("key")
+	if len(history) != 2 || history[0].Op != WriteSet || history[0].Value != "a" || history[1].Op != WriteDelete {
+		t.Fatalf("Unexpected history: %+v", history)
+	}
+	if history[0].Component != "" || history[0].Time.IsZero() {
+		t.Errorf("Expected writes outside the engine to have no component, got %+v", history[0])
+	}
+	if count := data.History("count"); len(count) != 1 || count[0].Op != WriteIncr || count[0].Value != int64(2) {
+		t.Errorf("Unexpected count history: %+v", count)
+	}
+
+	untracked := NewDataContext()
+	untracked.Set("key", "a")
+	if DataHistory(untracked) != nil {
+		t.Error("Expected no history for an untracked data context")
+	}
+}
+
+func TestTrackedDataContextScopes(t *testing.T) {
+	data := NewTrackedDataContext()
+	scope := data.Scope("s")
+	bound := bindWriter(scope, ExecutionInfo{Layer: "l", Component: "c", Attempt: 1})
+	bound.Set("result", 1)
+	if err := bound.Export("result"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	local := scope.(TrackedDataContext).History("result")
+	if len(local) != 1 || local[0].Scope != "s" || local[0].Component != "c" {
+		t.Errorf("Unexpected scope history: %+v", local)
+	}
+	exported := data.History("result")
+	if len(exported) != 1 || exported[0].Scope != "" || exported[0].Component != "c" {
+		t.Errorf("Expected export to be attributed to the writer, got %+v", exported)
+	}
+	if all := DataHistory(data)["result"]; len(all) != 2 || all[0].Scope != "s" {
+		t.Errorf("Unexpected merged history: %+v", all)
+	}
+	if data.Scope("s") != scope {
+		t.Error("Expected bound writes not to replace the cached scope")
+	}
+}
+
+func TestEngineTrackedDataHistory(t *testing.T) {
+	config := &Config{
+		Name: "tracked",
+		Layers: []LayerConfig{
+			{Name: "load", Mode: SerialMode, Components: []ComponentConfig{{Name: "loader", Type: "flaky"}}},
+			{Name: "transform", Mode: ParallelMode, Components: []ComponentConfig{{Name: "t", Type: "writer"}}},
+		},
+	}
+
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "flaky",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			component := &MockRetryableComponent{
+				retryConfig:     RetryConfig{MaxRetries: 2},
+				shouldRetryFunc: func(err error) bool { return true },
+			}
+			component.name = config.Name
+			component.executeFunc = func(ctx context.Context, data DataContext) error {
+				info, ok := ExecutionInfoFromContext(ctx)
+				if !ok {
+					t.Error("Expected execution info in context")
+				}
+				data.Set("value", info.Attempt)
+				if info.Attempt < 2 {
+					return errors.New("transient")
+				}
+				return nil
+			}
+			return component, nil
+		},
+	})
+	registry.Register(&MockComponentFactory{
+		componentType: "writer",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				data.Set("value", "final")
+				return nil
+			}}, nil
+		},
+	})
+
+	engine, err := NewEngine(config, registry, WithLogger(&MockLogger{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stats, err := engine.Execute(context.Background(), NewTrackedDataContext())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	history := stats.DataHistory["value"]
+	want := []struct {
+		layer, component string
+		attempt          int
+	}{
+		{"load", "loader", 1},
+		{"load", "loader", 2},
+		{"transform", "t", 1},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("Expected %d records, got %+v", len(want), history)
+	}
+	for i, w := range want {
+		if got := history[i]; got.Layer != w.layer || got.Component != w.component || got.Attempt != w.attempt {
+			t.Errorf("Record %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+
+	if _, err := json.Marshal(stats.DataHistory); err != nil {
+		t.Errorf("Expected history to be exportable as JSON, got %v", err)
+	}
+}
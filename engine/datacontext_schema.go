@@ -0,0 +1,197 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DataSchemaMode 违反键约束的写入的处理方式
+type DataSchemaMode string
+
+const (
+	DataSchemaReject DataSchemaMode = "reject" // 拒绝写入，引擎使写入的组件执行失败
+	DataSchemaWarn   DataSchemaMode = "warn"   // 照常写入，引擎记录违规与警告日志
+)
+
+// DataSchemaRegistry 按键登记 DataContext 值的约束，用于在组件之间约定数据契约
+// 约束使用与组件 config 校验相同的 Schema，按键名匹配，对所有作用域中的同名键生效
+type DataSchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string]*Schema
+}
+
+// NewDataSchemaRegistry 创建空的约束注册表
+func NewDataSchemaRegistry() *DataSchemaRegistry {
+	return &DataSchemaRegistry{schemas: make(map[string]*Schema)}
+}
+
+// Register 登记键的约束，同一个键再次登记时覆盖之前的约束
+func (r *DataSchemaRegistry) Register(key string, schema *Schema) error {
+	if key == "" || schema == nil {
+		return &DataContextError{Type: "invalid_schema", Key: key, Message: "key and schema cannot be empty"}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[key] = schema
+	return nil
+}
+
+// Lookup 返回键的约束
+func (r *DataSchemaRegistry) Lookup(key string) (*Schema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	schema, ok := r.schemas[key]
+	return schema, ok
+}
+
+// Keys 返回登记了约束的键，按名称排序
+func (r *DataSchemaRegistry) Keys() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]string, 0, len(r.schemas))
+	for key := range r.schemas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Validate 按键的约束校验值，未登记约束的键总是通过
+func (r *DataSchemaRegistry) Validate(key string, value interface{}) error {
+	schema, ok := r.Lookup(key)
+	if !ok {
+		return nil
+	}
+	if errs := schema.Validate(value, key); len(errs) > 0 {
+		return ValidationErrors(errs)
+	}
+	return nil
+}
+
+// schemaConfig 数据上下文关联的约束及本次执行中记录的违规
+type schemaConfig struct {
+	registry   *DataSchemaRegistry
+	mode       DataSchemaMode
+	mu         sync.Mutex
+	violations []schemaViolation
+}
+
+// schemaViolation 一次违规写入及其写入者
+type schemaViolation struct {
+	info ExecutionInfo
+	err  *DataContextError
+}
+
+// check 校验写入，违规时记录并返回 DataContextError；reject 为 true 时写入应被拒绝
+func (s *schemaConfig) check(key, scope string, value interface{}, writer *ExecutionInfo) (err *DataContextError, reject bool) {
+	cause := s.registry.Validate(key, value)
+	if cause == nil {
+		return nil, false
+	}
+	err = &DataContextError{Type: "schema_violation", Key: key, Message: cause.Error(), Cause: cause}
+	if scope != "" {
+		err.Message = fmt.Sprintf("in scope %s: %s", scope, cause.Error())
+	}
+	var info ExecutionInfo
+	if writer != nil {
+		info = *writer
+		err.Component = writer.Component
+		err.Layer = writer.Layer
+	}
+
+	s.mu.Lock()
+	s.violations = append(s.violations, schemaViolation{info: info, err: err})
+	s.mu.Unlock()
+	return err, s.mode != DataSchemaWarn
+}
+
+// rejects 判断写入是否会被拒绝，不记录违规
+func (s *schemaConfig) rejects(key string, value interface{}) bool {
+	return s != nil && s.mode != DataSchemaWarn && s.registry.Validate(key, value) != nil
+}
+
+// AttachDataSchemas 为 data 及其子作用域关联键约束：违反约束的写入会被记录，
+// mode 为 DataSchemaReject 时写入被拒绝（Incr/Append 返回错误，其余写操作不生效）
+// 记录的违规通过 DataViolations 读取。引擎中使用 WithDataSchemas
+func AttachDataSchemas(data DataContext, registry *DataSchemaRegistry, mode DataSchemaMode) error {
+	holder, ok := data.(interface {
+		attachSchemas(cfg *schemaConfig) func()
+	})
+	if !ok {
+		return &DataContextError{Type: "schema_unsupported", Message: fmt.Sprintf("%T does not support data schemas", data)}
+	}
+	holder.attachSchemas(&schemaConfig{registry: registry, mode: mode})
+	return nil
+}
+
+// DataViolations 返回 data（或其父作用域）关联的约束记录的违规，按发生顺序排列
+// 每个违规是 Type 为 schema_violation 的 DataContextError，Component 与 Layer 为写入的组件
+func DataViolations(data DataContext) []*DataContextError {
+	cfg := dataSchemasOf(data)
+	if cfg == nil {
+		return nil
+	}
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	if len(cfg.violations) == 0 {
+		return nil
+	}
+	errs := make([]*DataContextError, len(cfg.violations))
+	for i, v := range cfg.violations {
+		errs[i] = v.err
+	}
+	return errs
+}
+
+// attemptViolation 返回组件的一次执行尝试中被拒绝的第一个写入
+func attemptViolation(data DataContext, info ExecutionInfo) error {
+	cfg := dataSchemasOf(data)
+	if cfg == nil || cfg.mode == DataSchemaWarn {
+		return nil
+	}
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	for _, v := range cfg.violations {
+		if v.info == info {
+			return v.err
+		}
+	}
+	return nil
+}
+
+func dataSchemasOf(data DataContext) *schemaConfig {
+	if holder, ok := data.(interface{ dataSchemas() *schemaConfig }); ok {
+		return holder.dataSchemas()
+	}
+	return nil
+}
+
+// attachSchemas 关联约束并返回恢复之前设置的函数
+func (c *defaultDataContext) attachSchemas(cfg *schemaConfig) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous := c.schemas
+	c.schemas = cfg
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.schemas = previous
+	}
+}
+
+// dataSchemas 返回本作用域或最近的父作用域关联的约束
+func (c *defaultDataContext) dataSchemas() *schemaConfig {
+	c.mu.RLock()
+	cfg := c.schemas
+	c.mu.RUnlock()
+	if cfg != nil {
+		return cfg
+	}
+	return dataSchemasOf(c.parent)
+}
+
+// txDataContext 的写入在提交时经过父上下文校验
+func (t *txDataContext) dataSchemas() *schemaConfig {
+	return dataSchemasOf(t.parent)
+}
@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func newRecordSchemas(t *testing.T) *DataSchemaRegistry {
+	minLength := 1
+	schemas := NewDataSchemaRegistry()
+	if err := schemas.Register("transformed_data", &Schema{Type: SchemaType{"string"}, MinLength: &minLength}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	schemas.Register("records", &Schema{
+		Type:  SchemaType{"array"},
+		Items: &Schema{Type: SchemaType{"object"}, Required: []string{"id"}},
+	})
+	schemas.Register("count", &Schema{Type: SchemaType{"integer"}, Maximum: float64Ptr(2)})
+	return schemas
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func TestDataContextSchemaReject(t *testing.T) {
+	data := NewDataContext()
+	if err := AttachDataSchemas(data, newRecordSchemas(t), DataSchemaReject); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data.Set("transformed_data", "ok")
+	data.Set("transformed_data", "")
+	if v, _ := data.GetString("transformed_data"); v != "ok" {
+		t.Errorf("Expected the violating write to be rejected, got %q", v)
+	}
+
+	scope := data.Scope("s")
+	scope.Set("records", []map[string]interface{}{{"id": 1}, {"name": "x"}})
+	if scope.Has("records") {
+		t.Error("Expected schemas to apply inside scopes")
+	}
+
+	data.Incr("count", 2)
+	var dcErr *DataContextError
+	if _, err := data.Incr("count", 1); !errors.As(err, &dcErr) || dcErr.Type != "schema_violation" {
+		t.Errorf("Expected Incr to return schema_violation, got %v", err)
+	}
+	data.Set("unconstrained", "")
+
+	violations := DataViolations(data)
+	if len(violations) != 3 || violations[1].Key != "records" {
+		t.Fatalf("Expected 3 recorded violations, got %v", violations)
+	}
+	var verrs ValidationErrors
+	if !errors.As(violations[1], &verrs) || verrs[0].Field != "records[1].id" {
+		t.Errorf("Expected the cause to name the failing field, got %v", violations[1].Cause)
+	}
+}
+
+func TestDataContextSchemaWarn(t *testing.T) {
+	data := NewDataContext()
+	AttachDataSchemas(data, newRecordSchemas(t), DataSchemaWarn)
+	data.Set("transformed_data", 42)
+	if v, _ := data.Get("transformed_data"); v != 42 {
+		t.Errorf("Expected warn mode to keep the write, got %v", v)
+	}
+	if violations := DataViolations(data); len(violations) != 1 {
+		t.Errorf("Expected the violation to be recorded, got %v", violations)
+	}
+}
+
+func TestEngineDataSchemas(t *testing.T) {
+	config := &Config{
+		Name: "schemas",
+		Layers: []LayerConfig{
+			{Name: "transform", Mode: SerialMode, Components: []ComponentConfig{{Name: "transformer", Type: "writer"}}},
+		},
+	}
+
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "writer",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				// 写入被拒绝但组件没有检查
+				data.Set("transformed_data", "")
+				return nil
+			}}, nil
+		},
+	})
+
+	for _, tx := range []bool{false, true} {
+		options := []EngineOption{WithDataSchemas(newRecordSchemas(t), DataSchemaReject), WithLogger(&MockLogger{})}
+		if tx {
+			options = append(options, WithTransactionalData())
+		}
+		engine, err := NewEngine(config, registry, options...)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		data := NewDataContext()
+		stats, err := engine.Execute(context.Background(), data)
+
+		var dcErr *DataContextError
+		if !errors.As(err, &dcErr) || dcErr.Type != "schema_violation" || dcErr.Component != "transformer" || dcErr.Layer != "transform" {
+			t.Fatalf("Expected schema_violation naming the component (tx=%v), got %v", tx, err)
+		}
+		if data.Has("transformed_data") {
+			t.Errorf("Expected the violating write to be rejected (tx=%v)", tx)
+		}
+		if len(stats.DataViolations) != 1 {
+			t.Errorf("Expected the violation in the stats (tx=%v), got %v", tx, stats.DataViolations)
+		}
+		if DataViolations(data) != nil {
+			t.Errorf("Expected the schemas to be detached after the run (tx=%v)", tx)
+		}
+	}
+}
+
+func TestTxDataContextSchemaReject(t *testing.T) {
+	parent := NewDataContext()
+	if err := AttachDataSchemas(parent, newRecordSchemas(t), DataSchemaReject); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	group := newCommitGroup()
+
+	tx := newTxDataContext(parent, ExecutionInfo{Component: "c", Layer: "l"}, group)
+	tx.Set("count", 1)
+	tx.Set("transformed_data", "")
+	var dcErr *DataContextError
+	if err := tx.commit(); !errors.As(err, &dcErr) || dcErr.Type != "schema_violation" || dcErr.Component != "c" {
+		t.Fatalf("Expected schema_violation, got %v", err)
+	}
+	if parent.Has("count") || parent.Has("transformed_data") {
+		t.Errorf("Expected nothing to be committed, got %v", parent.Snapshot())
+	}
+	if violations := DataViolations(parent); len(violations) != 1 {
+		t.Errorf("Expected one recorded violation, got %v", violations)
+	}
+
+	// 被拒绝的提交不占用键，其他组件仍可提交
+	other := newTxDataContext(parent, ExecutionInfo{Component: "d", Layer: "l"}, group)
+	other.Set("count", 2)
+	if err := other.commit(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if v, _ := parent.Get("count"); v != 2 {
+		t.Errorf("Expected the other commit to succeed, got %v", v)
+	}
+}
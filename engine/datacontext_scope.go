@@ -0,0 +1,66 @@
+package engine
+
+import "fmt"
+
+// ScopeSnapshot 作用域树的快照，Data 只包含该作用域本地写入的键
+// 用于调试与输出，查看每个层/组件各自写入了哪些数据
+type ScopeSnapshot struct {
+	Name   string                 `json:"name"`
+	Path   string                 `json:"path"`
+	Data   map[string]interface{} `json:"data"`
+	Scopes []*ScopeSnapshot       `json:"scopes,omitempty"`
+}
+
+// SnapshotScopes 返回 data 及其所有子作用域的快照树
+// 不支持作用域树的 DataContext 实现只返回其 Snapshot
+func SnapshotScopes(data DataContext) *ScopeSnapshot {
+	if node, ok := data.(interface{ scopeSnapshot() *ScopeSnapshot }); ok {
+		return node.scopeSnapshot()
+	}
+	return &ScopeSnapshot{Data: data.Snapshot()}
+}
+
+// scopedDataContext 子作用域：本地存储由嵌入的 defaultDataContext 提供，
+// 读取未命中时逐级查询父作用域（defaultDataContext.parent）；写入只作用于本地
+// 删除本地键后，父作用域中的同名值会重新可见
+type scopedDataContext struct {
+	*defaultDataContext
+	name string
+}
+
+func (s *scopedDataContext) Scope(name string) DataContext {
+	return s.bindScope(s.childScope(s.unbound(), s.path, name))
+}
+
+// Export 将本地键的当前值写入父作用域，任一键在本地不存在时不发布任何键
+func (s *scopedDataContext) Export(keys ...string) error {
+	s.mu.RLock()
+	values := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		v, ok := s.localLocked(key)
+		if !ok {
+			s.mu.RUnlock()
+			return &DataContextError{
+				Type:    "key_not_found",
+				Key:     key,
+				Message: fmt.Sprintf("key is not set in scope %s", s.path),
+			}
+		}
+		values[key] = v
+	}
+	s.mu.RUnlock()
+
+	// 导出的写入同样归属于当前写入者
+	parent := s.parent
+	if s.writer != nil {
+		parent = bindWriter(parent, *s.writer)
+	}
+	for _, key := range keys {
+		parent.Set(key, values[key])
+	}
+	return nil
+}
+
+func (s *scopedDataContext) scopeSnapshot() *ScopeSnapshot {
+	return s.snapshotTree(s.name, s.localSnapshot())
+}
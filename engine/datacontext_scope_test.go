@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDataContextScope(t *testing.T) {
+	root := NewDataContextWith(map[string]interface{}{"input": "in", "result": "root"})
+	scope := root.Scope("team_a")
+	nested := scope.Scope("step")
+
+	if root.Scope("team_a") != scope {
+		t.Error("Expected Scope to return the same scope for the same name")
+	}
+	if v, _ := nested.GetString("input"); v != "in" {
+		t.Errorf("Expected read-through to root, got %q", v)
+	}
+
+	nested.Set("result", "nested")
+	if v, _ := root.GetString("result"); v != "root" {
+		t.Errorf("Expected root to be unchanged, got %q", v)
+	}
+	if v, _ := nested.GetString("result"); v != "nested" {
+		t.Errorf("Expected local value, got %q", v)
+	}
+	if snapshot := nested.Snapshot(); snapshot["input"] != "in" || snapshot["result"] != "nested" {
+		t.Errorf("Unexpected merged snapshot: %v", snapshot)
+	}
+
+	if err := nested.Export("result"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if v, _ := scope.GetString("result"); v != "nested" {
+		t.Errorf("Expected exported value in parent scope, got %q", v)
+	}
+	if v, _ := root.GetString("result"); v != "root" {
+		t.Errorf("Export should only publish one level up, got %q", v)
+	}
+
+	nested.Delete("result")
+	if v, _ := nested.GetString("result"); v != "nested" {
+		t.Errorf("Expected parent value to be visible after local delete, got %q", v)
+	}
+
+	var dcErr *DataContextError
+	if err := nested.Export("missing"); !errors.As(err, &dcErr) || dcErr.Type != "key_not_found" {
+		t.Errorf("Expected key_not_found, got %v", err)
+	}
+	if err := root.Export("input"); !errors.As(err, &dcErr) || dcErr.Type != "no_parent_scope" {
+		t.Errorf("Expected no_parent_scope, got %v", err)
+	}
+}
+
+func TestDataContextScopeAtomicReadThrough(t *testing.T) {
+	root := NewDataContextWith(map[string]interface{}{"count": 2, "errors": []interface{}{"root"}})
+	scope := root.Scope("s")
+
+	if n, err := scope.Incr("count", 1); err != nil || n != 3 {
+		t.Errorf("Expected 3, got %v %v", n, err)
+	}
+	if n, err := scope.Append("errors", "local"); err != nil || n != 2 {
+		t.Errorf("Expected 2, got %v %v", n, err)
+	}
+	if v, _ := root.Get("count"); v != 2 {
+		t.Errorf("Expected root count unchanged, got %v", v)
+	}
+	if _, stored := scope.SetIfAbsent("input", "x"); !stored {
+		t.Error("Expected SetIfAbsent to store locally")
+	}
+	if !scope.CompareAndSwap("count", int64(3), int64(4)) {
+		t.Error("Expected CompareAndSwap to succeed")
+	}
+}
+
+func TestEngineWithDataScopes(t *testing.T) {
+	config := &Config{
+		Name: "scoped",
+		Layers: []LayerConfig{
+			{Name: "produce", Mode: ParallelMode, Components: []ComponentConfig{
+				{Name: "a", Type: "writer"},
+				{Name: "b", Type: "writer"},
+			}},
+			{Name: "consume", Mode: SerialMode, Components: []ComponentConfig{{Name: "c", Type: "reader"}}},
+		},
+	}
+
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "writer",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				data.Set("result", config.Name)
+				data.Set(config.Name+"_out", true)
+				return data.Export(config.Name + "_out")
+			}}, nil
+		},
+	})
+	registry.Register(&MockComponentFactory{
+		componentType: "reader",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				if data.Has("result") {
+					t.Error("Unexported keys must not leak to other components")
+				}
+				if !data.Has("a_out") || !data.Has("b_out") {
+					t.Error("Expected exported keys to be visible downstream")
+				}
+				return nil
+			}}, nil
+		},
+	})
+
+	engine, err := NewEngine(config, registry, WithDataScopes(), WithLogger(&MockLogger{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data := NewDataContext()
+	if _, err := engine.Execute(context.Background(), data); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	tree := SnapshotScopes(data)
+	if len(tree.Scopes) != 3 {
+		t.Fatalf("Expected 3 component scopes, got %+v", tree.Scopes)
+	}
+	if tree.Scopes[0].Name != "consume.c" || tree.Scopes[1].Name != "produce.a" || tree.Scopes[1].Data["result"] != "a" {
+		t.Errorf("Unexpected scope tree: %+v %+v", tree.Scopes[0], tree.Scopes[1])
+	}
+	if tree.Data["a_out"] != true || tree.Data["result"] != nil {
+		t.Errorf("Unexpected root data: %v", tree.Data)
+	}
+}
@@ -0,0 +1,436 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// txDataContext 组件一次执行尝试的写时复制上下文：读取先查本次尝试的写入再查父上下文，
+// 写入（包括删除与 Export）缓存在本地，成功时由 commit 一次性写入父上下文，失败时直接丢弃
+type txDataContext struct {
+	mu      sync.Mutex
+	parent  DataContext
+	info    ExecutionInfo
+	group   *commitGroup
+	writes  map[string]txWrite
+	order   []string // 键首次写入的顺序，提交时按此顺序写入父上下文
+	exports []string
+	scopes  *defaultDataContext // 本次尝试中创建的子作用域，随尝试一起丢弃
+	done    bool
+}
+
+// txWrite 一个缓存的写入，deleted 表示删除，expires 非零时为 SetWithTTL 设置的过期时间
+// ops 非空时键只被 Incr/Append/Update 修改过：value 是本次尝试中可见的结果，提交时重放 ops 而不是写入 value
+type txWrite struct {
+	value   interface{}
+	deleted bool
+	expires time.Time
+	ops     []txOp
+}
+
+// txOp 缓存的原子操作，提交时在父上下文上重放，与同层其他组件对同一键的操作累加而不是相互覆盖
+type txOp func(parent DataContext) error
+
+// visible 判断写入的值当前是否可见
+func (w txWrite) visible() bool {
+	return !w.deleted && (w.expires.IsZero() || time.Now().Before(w.expires))
+}
+
+func newTxDataContext(parent DataContext, info ExecutionInfo, group *commitGroup) *txDataContext {
+	return &txDataContext{
+		parent: parent,
+		info:   info,
+		group:  group,
+		writes: make(map[string]txWrite),
+	}
+}
+
+// lookupLocked 在持有锁时读取键
+func (t *txDataContext) lookupLocked(key string) (interface{}, bool) {
+	if w, ok := t.writes[key]; ok {
+		if !w.visible() {
+			return nil, false
+		}
+		return w.value, true
+	}
+	return t.parent.Get(key)
+}
+
+// putLocked 在持有锁时缓存写入
+func (t *txDataContext) putLocked(key string, w txWrite) {
+	if _, ok := t.writes[key]; !ok {
+		t.order = append(t.order, key)
+	}
+	t.writes[key] = w
+}
+
+// modify 与 defaultDataContext.modify 相同的读-改-写语义，写入只缓存在本地
+func (t *txDataContext) modify(key string, fn func(old interface{}, ok bool) (value interface{}, write bool, err error)) (interface{}, bool, error) {
+	return t.modifyTTL(key, 0, fn)
+}
+
+// modifyTTL 与 modify 相同，ttl 大于 0 时同时记录过期时间
+func (t *txDataContext) modifyTTL(key string, ttl time.Duration, fn func(old interface{}, ok bool) (value interface{}, write bool, err error)) (interface{}, bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	old, ok := t.lookupLocked(key)
+	value, write, err := fn(old, ok)
+	if err != nil || !write {
+		return value, false, err
+	}
+	w := txWrite{value: value}
+	if ttl > 0 {
+		w.expires = time.Now().Add(ttl)
+	}
+	t.putLocked(key, w)
+	return value, true, nil
+}
+
+// accumulate 与 modify 相同，但键没有被本次尝试直接写入（Set/Delete 等）时同时记录 op，提交时重放
+func (t *txDataContext) accumulate(key string, op txOp, fn func(old interface{}, ok bool) (value interface{}, write bool, err error)) (interface{}, bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	old, ok := t.lookupLocked(key)
+	value, write, err := fn(old, ok)
+	if err != nil || !write {
+		return value, false, err
+	}
+	w := txWrite{value: value}
+	if prev, written := t.writes[key]; !written || prev.ops != nil {
+		w.ops = append(prev.ops, op)
+	}
+	t.putLocked(key, w)
+	return value, true, nil
+}
+
+func (t *txDataContext) Set(key string, value interface{}) {
+	t.modify(key, func(old interface{}, ok bool) (interface{}, bool, error) {
+		return value, true, nil
+	})
+}
+
+func (t *txDataContext) Get(key string) (interface{}, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lookupLocked(key)
+}
+
+func (t *txDataContext) GetString(key string) (string, bool) {
+	if v, ok := t.Get(key); ok {
+		if s, ok2 := v.(string); ok2 {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+func (t *txDataContext) Delete(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.lookupLocked(key); ok {
+		t.putLocked(key, txWrite{deleted: true})
+	}
+}
+
+func (t *txDataContext) Has(key string) bool {
+	_, ok := t.Get(key)
+	return ok
+}
+
+func (t *txDataContext) Snapshot() map[string]interface{} {
+	snapshot := t.parent.Snapshot()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, w := range t.writes {
+		if !w.visible() {
+			delete(snapshot, key)
+		} else {
+			snapshot[key] = w.value
+		}
+	}
+	return snapshot
+}
+
+func (t *txDataContext) Update(key string, fn func(old interface{}, ok bool) interface{}) interface{} {
+	op := func(parent DataContext) error {
+		parent.Update(key, fn)
+		return nil
+	}
+	value, _, _ := t.accumulate(key, op, func(old interface{}, ok bool) (interface{}, bool, error) {
+		return fn(old, ok), true, nil
+	})
+	return value
+}
+
+func (t *txDataContext) CompareAndSwap(key string, old, new interface{}) bool {
+	_, swapped, _ := t.modify(key, func(current interface{}, ok bool) (interface{}, bool, error) {
+		return new, ok && reflect.DeepEqual(current, old), nil
+	})
+	return swapped
+}
+
+func (t *txDataContext) SetIfAbsent(key string, value interface{}) (interface{}, bool) {
+	return mustModify(t.modify(key, func(current interface{}, ok bool) (interface{}, bool, error) {
+		if ok {
+			return current, false, nil
+		}
+		return value, true, nil
+	}))
+}
+
+func (t *txDataContext) Incr(key string, delta int64) (int64, error) {
+	op := func(parent DataContext) error {
+		_, err := parent.Incr(key, delta)
+		return err
+	}
+	value, _, err := t.accumulate(key, op, func(current interface{}, ok bool) (interface{}, bool, error) {
+		n, err := incrValue(key, current, delta)
+		return n, err == nil, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return value.(int64), nil
+}
+
+func (t *txDataContext) Append(key string, values ...interface{}) (int, error) {
+	op := func(parent DataContext) error {
+		_, err := parent.Append(key, values...)
+		return err
+	}
+	list, _, err := t.accumulate(key, op, func(current interface{}, ok bool) (interface{}, bool, error) {
+		list, err := appendValues(key, current, values)
+		return list, err == nil, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return reflect.ValueOf(list).Len(), nil
+}
+
+// Scope 返回本次尝试中的子作用域，其 Export 发布到本事务，随事务一起提交或丢弃
+func (t *txDataContext) Scope(name string) DataContext {
+	return t.scopeStore().childScope(t, scopePathOf(t.parent), name)
+}
+
+// scopeStore 返回保存本次尝试子作用域的存储
+func (t *txDataContext) scopeStore() *defaultDataContext {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.scopes == nil {
+		t.scopes = &defaultDataContext{dataStore: &dataStore{data: make(map[string]interface{})}}
+	}
+	return t.scopes
+}
+
+// Export 缓存导出，提交时在写入之后调用父上下文的 Export
+func (t *txDataContext) Export(keys ...string) error {
+	// 父上下文不支持导出（如根上下文）时立即报告，与非事务模式一致
+	if err := t.parent.Export(); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, key := range keys {
+		if _, ok := t.lookupLocked(key); !ok {
+			return &DataContextError{
+				Type:    "key_not_found",
+				Key:     key,
+				Message: fmt.Sprintf("key is not set in scope %s", scopePathOf(t.parent)),
+			}
+		}
+	}
+	t.exports = append(t.exports, keys...)
+	return nil
+}
+
+// Wait 键已在本次尝试中写入时立即返回，否则等待父上下文
+func (t *txDataContext) Wait(ctx context.Context, key string) (interface{}, error) {
+	if v, ok := t.Get(key); ok {
+		return v, nil
+	}
+	return t.parent.Wait(ctx, key)
+}
+
+// Watch 观察父上下文的变更，本次尝试的写入在提交后才会出现
+func (t *txDataContext) Watch(ctx context.Context, key string) <-chan Change {
+	return t.parent.Watch(ctx, key)
+}
+
+// SetWithCleanup 缓存写入，清理函数立即登记到父上下文：即使本次尝试失败，
+// 已创建的资源也需要在执行结束时释放
+func (t *txDataContext) SetWithCleanup(key string, value interface{}, cleanup func()) {
+	t.Set(key, value)
+	if cleanup == nil {
+		return
+	}
+	t.cleanupStack().push(cleanupEntry{key: key, scope: scopePathOf(t.parent), component: t.info.Component, fn: cleanup})
+}
+
+// SetWithTTL 缓存写入，过期时间从写入时开始计算，提交时按剩余时间写入父上下文
+func (t *txDataContext) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	t.modifyTTL(key, ttl, func(old interface{}, ok bool) (interface{}, bool, error) {
+		return value, true, nil
+	})
+}
+
+// cleanupStack 返回父上下文的清理函数栈，父上下文不支持时使用本事务的作用域存储中的
+func (t *txDataContext) cleanupStack() *cleanupStack {
+	if holder, ok := t.parent.(interface{ cleanupStack() *cleanupStack }); ok {
+		return holder.cleanupStack()
+	}
+	return t.scopeStore().cleanupStack()
+}
+
+// commit 将缓存的写入应用到父上下文；同一并行层中其他组件已提交相同的键时返回冲突且不写入任何键
+// 只经过 Incr/Append/Update 的键不参与冲突检测，提交时在父上下文上重放这些操作
+func (t *txDataContext) commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done {
+		return nil
+	}
+	t.done = true
+
+	// 拒绝模式下先校验所有写入，任一违规时整体不提交，避免只写入部分键
+	if err := t.validateLocked(); err != nil {
+		return err
+	}
+
+	if t.group != nil {
+		path := scopePathOf(t.parent)
+		claims := make([]string, 0, len(t.order)+len(t.exports))
+		for _, key := range t.order {
+			if t.writes[key].ops == nil {
+				claims = append(claims, commitKey(path, key))
+			}
+		}
+		for _, key := range t.exports {
+			claims = append(claims, commitKey(parentScopePath(path), key))
+		}
+		if err := t.group.claim(t.info, claims); err != nil {
+			return err
+		}
+	}
+
+	var replayErr error
+	for _, key := range t.order {
+		w := t.writes[key]
+		switch {
+		case w.ops != nil:
+			for _, op := range w.ops {
+				if err := op(t.parent); err != nil && replayErr == nil {
+					replayErr = err
+				}
+			}
+		case w.deleted:
+			t.parent.Delete(key)
+		case !w.expires.IsZero():
+			// 按剩余时间写入，提交前已过期的写入等同于删除
+			if ttl := time.Until(w.expires); ttl > 0 {
+				t.parent.SetWithTTL(key, w.value, ttl)
+			} else {
+				t.parent.Delete(key)
+			}
+		default:
+			t.parent.Set(key, w.value)
+		}
+	}
+	if replayErr != nil {
+		return replayErr
+	}
+	if len(t.exports) > 0 {
+		return t.parent.Export(t.exports...)
+	}
+	return nil
+}
+
+// validateLocked 按父上下文关联的约束校验缓存的写入，返回第一个违规
+// 警告模式下不预先校验，违规在提交的写入中照常记录
+func (t *txDataContext) validateLocked() error {
+	schemas := dataSchemasOf(t.parent)
+	if schemas == nil || schemas.mode == DataSchemaWarn {
+		return nil
+	}
+	path := scopePathOf(t.parent)
+	for _, key := range t.order {
+		w := t.writes[key]
+		if w.deleted {
+			continue
+		}
+		if violation, reject := schemas.check(key, path, w.value, &t.info); reject {
+			return violation
+		}
+	}
+	return nil
+}
+
+// commitGroup 检测同一层中并发执行的组件提交相同的键
+type commitGroup struct {
+	mu     sync.Mutex
+	owners map[string]string // 作用域路径与键 -> 提交它的组件
+}
+
+func newCommitGroup() *commitGroup {
+	return &commitGroup{owners: make(map[string]string)}
+}
+
+// claim 登记组件提交的键，任一键已被其他组件提交时不登记并返回 DataContextError
+func (g *commitGroup) claim(info ExecutionInfo, keys []string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, key := range keys {
+		if owner, ok := g.owners[key]; ok && owner != info.Component {
+			path, name := splitCommitKey(key)
+			message := fmt.Sprintf("key was already committed by component %s in the same layer", owner)
+			if path != "" {
+				message = fmt.Sprintf("key was already committed to scope %s by component %s in the same layer", path, owner)
+			}
+			return &DataContextError{
+				Type:      "commit_conflict",
+				Key:       name,
+				Message:   message,
+				Component: info.Component,
+				Layer:     info.Layer,
+			}
+		}
+	}
+	for _, key := range keys {
+		g.owners[key] = info.Component
+	}
+	return nil
+}
+
+// commitKey 组合作用域路径与键
+func commitKey(path, key string) string {
+	return path + "\x00" + key
+}
+
+func splitCommitKey(key string) (string, string) {
+	i := strings.IndexByte(key, 0)
+	return key[:i], key[i+1:]
+}
+
+// scopePathOf 返回上下文的作用域路径，根上下文或未知实现为空
+func scopePathOf(data DataContext) string {
+	if scoped, ok := data.(interface{ scopePath() string }); ok {
+		return scoped.scopePath()
+	}
+	return ""
+}
+
+// parentScopePath 返回父作用域的路径
+func parentScopePath(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+func (c *defaultDataContext) scopePath() string {
+	return c.path
+}
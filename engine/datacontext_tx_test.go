@@ -0,0 +1,271 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTxDataContext(t *testing.T) {
+	parent := NewDataContextWith(map[string]interface{}{"keep": 1, "drop": 2, "count": 1})
+	tx := newTxDataContext(parent, ExecutionInfo{Layer: "l", Component: "c", Attempt: 1}, nil)
+
+	tx.Set("new", "value")
+	tx.Delete("drop")
+	if n, err := tx.Incr("count", 1); err != nil || n != 2 {
+		t.Errorf("Expected read-through increment, got %d %v", n, err)
+	}
+
+	if tx.Has("drop") || !tx.Has("keep") {
+		t.Error("Expected tx reads to apply local writes over the parent")
+	}
+	if parent.Has("new") || !parent.Has("drop") {
+		t.Error("Expected writes to stay in the transaction before commit")
+	}
+	want := map[string]interface{}{"keep": 1, "new": "value", "count": int64(2)}
+	if snapshot := tx.Snapshot(); !reflect.DeepEqual(snapshot, want) {
+		t.Errorf("Unexpected tx snapshot: %v", snapshot)
+	}
+
+	if err := tx.commit(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if snapshot := parent.Snapshot(); !reflect.DeepEqual(snapshot, want) {
+		t.Errorf("Unexpected parent after commit: %v", snapshot)
+	}
+}
+
+func TestCommitGroupConflict(t *testing.T) {
+	parent := NewDataContext()
+	group := newCommitGroup()
+	a := newTxDataContext(parent, ExecutionInfo{Layer: "l", Component: "a"}, group)
+	b := newTxDataContext(parent, ExecutionInfo{Layer: "l", Component: "b"}, group)
+	a.Set("result", "a")
+	b.Set("result", "b")
+	b.Set("other", "b")
+
+	if err := a.commit(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var dcErr *DataContextError
+	if err := b.commit(); !errors.As(err, &dcErr) || dcErr.Type != "commit_conflict" || dcErr.Key != "result" || dcErr.Component != "b" {
+		t.Fatalf("Expected commit_conflict for b, got %v", err)
+	}
+	if v, _ := parent.Get("result"); v != "a" || parent.Has("other") {
+		t.Errorf("Expected the conflicting commit to write nothing, got %v", parent.Snapshot())
+	}
+}
+
+func TestEngineTransactionalRetry(t *testing.T) {
+	config := &Config{
+		Name: "tx",
+		Layers: []LayerConfig{
+			{Name: "load", Mode: SerialMode, Components: []ComponentConfig{{Name: "flaky", Type: "flaky"}}},
+		},
+	}
+
+	var seenPartial []bool
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "flaky",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			component := &MockRetryableComponent{
+				retryConfig:     RetryConfig{MaxRetries: 1},
+				shouldRetryFunc: func(err error) bool { return true },
+			}
+			component.name = config.Name
+			component.executeFunc = func(ctx context.Context, data DataContext) error {
+				seenPartial = append(seenPartial, data.Has("partial"))
+				info, _ := ExecutionInfoFromContext(ctx)
+				if info.Attempt == 1 {
+					data.Set("partial", true)
+					return errors.New("half-written")
+				}
+				data.Set("result", "ok")
+				return nil
+			}
+			return component, nil
+		},
+	})
+
+	engine, err := NewEngine(config, registry, WithTransactionalData(), WithLogger(&MockLogger{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data := NewTrackedDataContext()
+	if _, err := engine.Execute(context.Background(), data); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(seenPartial, []bool{false, false}) {
+		t.Errorf("Expected the retry not to see the failed attempt's writes, got %v", seenPartial)
+	}
+	if data.Has("partial") {
+		t.Error("Expected the failed attempt's writes to be discarded")
+	}
+	if history := data.History("result"); len(history) != 1 || history[0].Attempt != 2 {
+		t.Errorf("Expected the committed write to be attributed to attempt 2, got %+v", history)
+	}
+}
+
+func TestEngineTransactionalParallelConflict(t *testing.T) {
+	config := &Config{
+		Name: "tx",
+		Layers: []LayerConfig{
+			{Name: "fan", Mode: ParallelMode, Components: []ComponentConfig{
+				{Name: "a", Type: "writer"},
+				{Name: "b", Type: "writer"},
+			}},
+		},
+	}
+
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "writer",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				data.Set(config.Name+"_own", true)
+				data.Set("result", config.Name)
+				return nil
+			}}, nil
+		},
+	})
+
+	engine, err := NewEngine(config, registry, WithTransactionalData(), WithLogger(&MockLogger{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data := NewDataContext()
+	_, err = engine.Execute(context.Background(), data)
+
+	var dcErr *DataContextError
+	if !errors.As(err, &dcErr) || dcErr.Type != "commit_conflict" || dcErr.Key != "result" {
+		t.Fatalf("Expected commit_conflict, got %v", err)
+	}
+	winner, _ := data.GetString("result")
+	loser := map[string]string{"a": "b", "b": "a"}[winner]
+	if !data.Has(winner+"_own") || data.Has(loser+"_own") || dcErr.Component != loser {
+		t.Errorf("Expected only the first committer's writes, got %v (conflict reported for %s)", data.Snapshot(), dcErr.Component)
+	}
+}
+
+func TestEngineTransactionalParallelAccumulate(t *testing.T) {
+	config := &Config{
+		Name: "tx",
+		Layers: []LayerConfig{
+			{Name: "fan", Mode: ParallelMode, Components: []ComponentConfig{
+				{Name: "a", Type: "appender"},
+				{Name: "b", Type: "appender"},
+			}},
+		},
+	}
+
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "appender",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				if _, err := data.Append("errors", config.Name); err != nil {
+					return err
+				}
+				if _, err := data.Incr("count", 1); err != nil {
+					return err
+				}
+				data.Update("total", func(old interface{}, ok bool) interface{} {
+					n, _ := old.(int)
+					return n + 10
+				})
+				return nil
+			}}, nil
+		},
+	})
+
+	engine, err := NewEngine(config, registry, WithTransactionalData(), WithLogger(&MockLogger{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data := NewDataContext()
+	if _, err := engine.Execute(context.Background(), data); err != nil {
+		t.Fatalf("Expected accumulating writes not to conflict, got %v", err)
+	}
+
+	errs, _ := data.Get("errors")
+	if list, ok := errs.([]interface{}); !ok || len(list) != 2 {
+		t.Errorf("Expected both appends to be committed, got %v", errs)
+	}
+	if v, _ := data.Get("count"); v != int64(2) {
+		t.Errorf("Expected both increments to be committed, got %v", v)
+	}
+	if v, _ := data.Get("total"); v != 20 {
+		t.Errorf("Expected both updates to be replayed, got %v", v)
+	}
+}
+
+func TestTxDataContextAccumulateAfterSet(t *testing.T) {
+	parent := NewDataContextWith(map[string]interface{}{"count": int64(5)})
+	group := newCommitGroup()
+	a := newTxDataContext(parent, ExecutionInfo{Layer: "l", Component: "a"}, group)
+	b := newTxDataContext(parent, ExecutionInfo{Layer: "l", Component: "b"}, group)
+
+	// a 先直接写入再累加：整体是一次普通写入，参与冲突检测
+	a.Set("count", int64(0))
+	a.Incr("count", 1)
+	b.Incr("count", 1)
+	if n, _ := b.Incr("count", 1); n != 7 {
+		t.Errorf("Expected tx reads to see the accumulated value, got %d", n)
+	}
+
+	if err := b.commit(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if v, _ := parent.Get("count"); v != int64(7) {
+		t.Errorf("Expected b's increments to be replayed, got %v", v)
+	}
+	if err := a.commit(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if v, _ := parent.Get("count"); v != int64(1) {
+		t.Errorf("Expected a's direct write to overwrite, got %v", v)
+	}
+}
+
+func TestEngineTransactionalScopes(t *testing.T) {
+	config := &Config{
+		Name: "tx",
+		Layers: []LayerConfig{
+			{Name: "fan", Mode: ParallelMode, Components: []ComponentConfig{
+				{Name: "a", Type: "writer"},
+				{Name: "b", Type: "writer"},
+			}},
+		},
+	}
+
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "writer",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				// 各自作用域中的同名键不冲突
+				data.Set("result", config.Name)
+				data.Set(config.Name+"_out", true)
+				return data.Export(config.Name + "_out")
+			}}, nil
+		},
+	})
+
+	engine, err := NewEngine(config, registry, WithTransactionalData(), WithDataScopes(), WithLogger(&MockLogger{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data := NewDataContext()
+	if _, err := engine.Execute(context.Background(), data); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !data.Has("a_out") || !data.Has("b_out") || data.Has("result") {
+		t.Errorf("Expected exports to be committed to the root, got %v", data.Snapshot())
+	}
+	if v, _ := data.Scope(ComponentScopeName("fan", "a")).GetString("result"); v != "a" {
+		t.Errorf("Expected scope-local write to be committed, got %q", v)
+	}
+}
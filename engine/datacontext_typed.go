@@ -0,0 +1,266 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Get 从 DataContext 中读取类型为 T 的值
+// 值的类型与 T 不一致时按以下规则转换，无法转换或键不存在时返回 false：
+//   - 数值之间互相转换（如 JSON 解码得到的 float64 转为 int），转换为整数时要求值为整数且不溢出
+//   - time.Duration 接受时长字符串（如 "5s"）或整数纳秒
+//   - []byte 与 string 互相转换，*Blob 读取其内容
+//   - map[string]interface{} 接受任意字符串键的 map
+func Get[T any](data DataContext, key string) (T, bool) {
+	var zero T
+	value, ok := data.Get(key)
+	if !ok {
+		return zero, false
+	}
+	return convertTo[T](value)
+}
+
+// MustGet 读取类型为 T 的值，键不存在或无法转换时 panic
+// 适用于由上游组件保证写入的键，错误信息包含键名与实际类型
+func MustGet[T any](data DataContext, key string) T {
+	value, ok := data.Get(key)
+	if !ok {
+		panic(fmt.Sprintf("data context key %q not found", key))
+	}
+	converted, ok := convertTo[T](value)
+	if !ok {
+		panic(fmt.Sprintf("data context key %q has type %T, cannot convert to %s", key, value, typeName[T]()))
+	}
+	return converted
+}
+
+// GetOr 读取类型为 T 的值，键不存在或无法转换时返回 def
+func GetOr[T any](data DataContext, key string, def T) T {
+	if value, ok := Get[T](data, key); ok {
+		return value
+	}
+	return def
+}
+
+// GetInt 读取 int 值，转换规则见 Get
+func GetInt(data DataContext, key string) (int, bool) {
+	return Get[int](data, key)
+}
+
+// GetInt64 读取 int64 值，转换规则见 Get
+func GetInt64(data DataContext, key string) (int64, bool) {
+	return Get[int64](data, key)
+}
+
+// GetFloat 读取 float64 值，转换规则见 Get
+func GetFloat(data DataContext, key string) (float64, bool) {
+	return Get[float64](data, key)
+}
+
+// GetBool 读取 bool 值
+func GetBool(data DataContext, key string) (bool, bool) {
+	return Get[bool](data, key)
+}
+
+// GetDuration 读取 time.Duration 值，接受时长字符串或整数纳秒
+func GetDuration(data DataContext, key string) (time.Duration, bool) {
+	return Get[time.Duration](data, key)
+}
+
+// GetBytes 读取 []byte 值，string 会被转换为 []byte，*Blob 会读取其全部内容
+func GetBytes(data DataContext, key string) ([]byte, bool) {
+	return Get[[]byte](data, key)
+}
+
+// GetMap 读取 map[string]interface{} 值，接受任意字符串键的 map
+func GetMap(data DataContext, key string) (map[string]interface{}, bool) {
+	return Get[map[string]interface{}](data, key)
+}
+
+// Key 类型化的键，生产者与消费者共享同一个 Key 变量即可在编译期保证读写类型一致
+//
+//	var UserIDKey = engine.NewKey[int64]("user_id")
+//	UserIDKey.Set(data, 42)
+//	id, ok := UserIDKey.Get(data)
+type Key[T any] struct {
+	name string
+}
+
+// NewKey 创建类型化的键
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// Name 返回键名
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// String 返回键名
+func (k Key[T]) String() string {
+	return k.name
+}
+
+// Set 写入值
+func (k Key[T]) Set(data DataContext, value T) {
+	data.Set(k.name, value)
+}
+
+// Get 读取值，转换规则见 Get
+func (k Key[T]) Get(data DataContext) (T, bool) {
+	return Get[T](data, k.name)
+}
+
+// MustGet 读取值，键不存在或无法转换时 panic
+func (k Key[T]) MustGet(data DataContext) T {
+	return MustGet[T](data, k.name)
+}
+
+// GetOr 读取值，键不存在或无法转换时返回 def
+func (k Key[T]) GetOr(data DataContext, def T) T {
+	return GetOr(data, k.name, def)
+}
+
+// Has 判断键是否存在
+func (k Key[T]) Has(data DataContext) bool {
+	return data.Has(k.name)
+}
+
+// Delete 删除键
+func (k Key[T]) Delete(data DataContext) {
+	data.Delete(k.name)
+}
+
+// typeName 返回类型参数的名称
+func typeName[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
+
+// convertTo 将值转换为类型 T
+func convertTo[T any](value interface{}) (T, bool) {
+	var zero T
+	if typed, ok := value.(T); ok {
+		return typed, true
+	}
+	if value == nil {
+		return zero, false
+	}
+
+	target := reflect.TypeOf((*T)(nil)).Elem()
+	converted, ok := convertValue(reflect.ValueOf(value), target)
+	if !ok {
+		return zero, false
+	}
+	return converted.Interface().(T), true
+}
+
+// convertValue 按 Get 的规则将值转换为目标类型
+func convertValue(rv reflect.Value, target reflect.Type) (reflect.Value, bool) {
+	if blob, ok := rv.Interface().(*Blob); ok {
+		if target.Kind() != reflect.String && !(target.Kind() == reflect.Slice && target.Elem().Kind() == reflect.Uint8) {
+			return reflect.Value{}, false
+		}
+		content, err := blob.Bytes()
+		if err != nil {
+			return reflect.Value{}, false
+		}
+		if target.Kind() == reflect.Slice {
+			return reflect.ValueOf(content).Convert(target), true
+		}
+		rv = reflect.ValueOf(content)
+	}
+	if target == durationType {
+		if s, ok := rv.Interface().(string); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return reflect.Value{}, false
+			}
+			return reflect.ValueOf(d), true
+		}
+	}
+
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = rv.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if rv.Uint() > math.MaxInt64 {
+				return reflect.Value{}, false
+			}
+			n = int64(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			f := rv.Float()
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+				return reflect.Value{}, false
+			}
+			n = int64(f)
+		default:
+			return reflect.Value{}, false
+		}
+		out := reflect.New(target).Elem()
+		if out.OverflowInt(n) {
+			return reflect.Value{}, false
+		}
+		out.SetInt(n)
+		return out, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if rv.Int() < 0 {
+				return reflect.Value{}, false
+			}
+			n = uint64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			n = rv.Uint()
+		case reflect.Float32, reflect.Float64:
+			f := rv.Float()
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+				return reflect.Value{}, false
+			}
+			n = uint64(f)
+		default:
+			return reflect.Value{}, false
+		}
+		out := reflect.New(target).Elem()
+		if out.OverflowUint(n) {
+			return reflect.Value{}, false
+		}
+		out.SetUint(n)
+		return out, true
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat64(rv.Interface())
+		if !ok {
+			return reflect.Value{}, false
+		}
+		out := reflect.New(target).Elem()
+		// 超出 float32 范围的值不能静默变成 ±Inf
+		if out.OverflowFloat(f) {
+			return reflect.Value{}, false
+		}
+		out.SetFloat(f)
+		return out, true
+	case reflect.String:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return reflect.ValueOf(string(rv.Bytes())).Convert(target), true
+		}
+		if rv.Kind() == reflect.String {
+			return rv.Convert(target), true
+		}
+	case reflect.Slice:
+		if target.Elem().Kind() == reflect.Uint8 && rv.Kind() == reflect.String {
+			return reflect.ValueOf([]byte(rv.String())).Convert(target), true
+		}
+	case reflect.Map:
+		if target == reflect.TypeOf(map[string]interface{}(nil)) {
+			if m, ok := toStringMap(rv.Interface()); ok {
+				return reflect.ValueOf(m), true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDataContextGenericGet(t *testing.T) {
+	data := NewDataContextWith(map[string]interface{}{
+		"count":      float64(3),
+		"ratio":      0.5,
+		"big":        int64(1) << 40,
+		"negative":   -1,
+		"enabled":    true,
+		"name":       "kflow",
+		"payload":    []byte("raw"),
+		"timeout":    "1m30s",
+		"nanos":      float64(time.Second),
+		"labels":     map[string]string{"env": "prod"},
+		"fractional": 1.5,
+		"huge":       1e300,
+	})
+
+	if v, ok := GetInt(data, "count"); !ok || v != 3 {
+		t.Errorf("Expected float64 3 to convert to int, got %v %v", v, ok)
+	}
+	if _, ok := GetInt(data, "fractional"); ok {
+		t.Error("Expected fractional float not to convert to int")
+	}
+	if v, ok := GetInt64(data, "big"); !ok || v != 1<<40 {
+		t.Errorf("Unexpected int64: %v %v", v, ok)
+	}
+	if _, ok := Get[int8](data, "big"); ok {
+		t.Error("Expected overflow to fail")
+	}
+	if _, ok := Get[uint](data, "negative"); ok {
+		t.Error("Expected negative value not to convert to uint")
+	}
+	if v, ok := GetFloat(data, "count"); !ok || v != 3 {
+		t.Errorf("Unexpected float: %v %v", v, ok)
+	}
+	if _, ok := Get[float32](data, "huge"); ok {
+		t.Error("Expected float32 overflow to fail")
+	}
+	if v, ok := Get[float32](data, "ratio"); !ok || v != 0.5 {
+		t.Errorf("Unexpected float32: %v %v", v, ok)
+	}
+	if v, ok := GetBool(data, "enabled"); !ok || !v {
+		t.Errorf("Unexpected bool: %v %v", v, ok)
+	}
+	if _, ok := GetBool(data, "name"); ok {
+		t.Error("Expected string not to convert to bool")
+	}
+	if v, ok := GetDuration(data, "timeout"); !ok || v != 90*time.Second {
+		t.Errorf("Unexpected duration: %v %v", v, ok)
+	}
+	if v, ok := GetDuration(data, "nanos"); !ok || v != time.Second {
+		t.Errorf("Unexpected duration from nanoseconds: %v %v", v, ok)
+	}
+	if v, ok := GetBytes(data, "name"); !ok || string(v) != "kflow" {
+		t.Errorf("Unexpected bytes: %v %v", v, ok)
+	}
+	if v, ok := Get[string](data, "payload"); !ok || v != "raw" {
+		t.Errorf("Unexpected string from bytes: %v %v", v, ok)
+	}
+	if v, ok := GetMap(data, "labels"); !ok || v["env"] != "prod" {
+		t.Errorf("Unexpected map: %v %v", v, ok)
+	}
+	if _, ok := Get[int](data, "missing"); ok {
+		t.Error("Expected missing key to return false")
+	}
+	if v := GetOr(data, "missing", 7); v != 7 {
+		t.Errorf("Expected default 7, got %v", v)
+	}
+	if v := GetOr(data, "count", 7); v != 3 {
+		t.Errorf("Expected stored value 3, got %v", v)
+	}
+}
+
+func TestDataContextMustGet(t *testing.T) {
+	data := NewDataContextWith(map[string]interface{}{"name": "kflow"})
+	if v := MustGet[string](data, "name"); v != "kflow" {
+		t.Errorf("Unexpected value: %v", v)
+	}
+
+	for _, key := range []string{"missing", "name"} {
+		func() {
+			defer func() {
+				r := recover()
+				if r == nil || !strings.Contains(r.(string), key) {
+					t.Errorf("Expected panic mentioning %q, got %v", key, r)
+				}
+			}()
+			MustGet[int](data, key)
+		}()
+	}
+}
+
+func TestDataContextKey(t *testing.T) {
+	type user struct{ ID int }
+	userKey := NewKey[*user]("user")
+	retriesKey := NewKey[int]("retries")
+
+	data := NewDataContext()
+	userKey.Set(data, &user{ID: 42})
+	data.Set(retriesKey.Name(), float64(2))
+
+	if u, ok := userKey.Get(data); !ok || u.ID != 42 {
+		t.Errorf("Unexpected user: %v %v", u, ok)
+	}
+	if v := retriesKey.MustGet(data); v != 2 {
+		t.Errorf("Unexpected retries: %v", v)
+	}
+	retriesKey.Delete(data)
+	if retriesKey.Has(data) || retriesKey.GetOr(data, 5) != 5 {
+		t.Error("Expected retries to be deleted")
+	}
+	if retriesKey.String() != "retries" {
+		t.Errorf("Unexpected key name: %s", retriesKey)
+	}
+}
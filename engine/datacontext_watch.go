@@ -0,0 +1,225 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Change DataContext 中一个键的变更
+type Change struct {
+	Key       string      `json:"key"`
+	Op        WriteOp     `json:"op"`
+	Value     interface{} `json:"value,omitempty"` // 写入的新值，删除时为 nil
+	Old       interface{} `json:"old,omitempty"`   // 写入前可见的旧值
+	Existed   bool        `json:"existed"`         // 写入前键是否可见
+	Deleted   bool        `json:"deleted,omitempty"`
+	Scope     string      `json:"scope,omitempty"` // 发生写入的作用域路径，根上下文为空
+	Layer     string      `json:"layer,omitempty"` // 写入组件所在的层，引擎之外的写入为空
+	Component string      `json:"component,omitempty"`
+	Attempt   int         `json:"attempt,omitempty"`
+	Time      time.Time   `json:"time"`
+}
+
+// DataChangeMiddleware 可选的中间件扩展：观察执行期间对 DataContext 的所有写入，
+// 包括传给 Execute 的上下文及其所有子作用域（如 WithDataScopes 创建的组件作用域）
+// OnDataChange 在写入方的 goroutine 中同步调用，写锁已释放，但不应阻塞
+type DataChangeMiddleware interface {
+	Middleware
+	OnDataChange(ctx context.Context, change Change)
+}
+
+// changeNotifier 支持变更订阅的 DataContext 实现
+type changeNotifier interface {
+	// subscribe 订阅本上下文可见的变更（包括父作用域中未被本地覆盖的键）
+	subscribe(fn func(Change)) func()
+	// subscribeAll 订阅本上下文及其所有子作用域中发生的写入
+	subscribeAll(fn func(Change)) func()
+}
+
+// watcherSet 变更监听器集合，零值可用
+type watcherSet struct {
+	mu    sync.Mutex
+	next  int
+	local map[int]func(Change)
+	tree  map[int]func(Change)
+}
+
+// add 注册监听器并返回取消函数
+func (w *watcherSet) add(tree bool, fn func(Change)) func() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	target := &w.local
+	if tree {
+		target = &w.tree
+	}
+	if *target == nil {
+		*target = make(map[int]func(Change))
+	}
+	id := w.next
+	w.next++
+	(*target)[id] = fn
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			delete(*target, id)
+		})
+	}
+}
+
+// listeners 返回监听器的副本，调用时不持有锁
+func (w *watcherSet) listeners(tree bool) []func(Change) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	source := w.local
+	if tree {
+		source = w.tree
+	}
+	result := make([]func(Change), 0, len(source))
+	for _, fn := range source {
+		result = append(result, fn)
+	}
+	return result
+}
+
+// notify 在写锁释放后通知本上下文的监听器，并沿作用域树向上通知
+// 不持有锁通知使监听器可以读取上下文，代价是并发写入方的通知之间可能乱序
+func (c *defaultDataContext) notify(change Change) {
+	for _, fn := range c.watchers.listeners(false) {
+		fn(change)
+	}
+	c.notifyTree(change)
+}
+
+// notifyTree 通知订阅整棵子树的监听器，再交给上级作用域
+func (c *defaultDataContext) notifyTree(change Change) {
+	for _, fn := range c.watchers.listeners(true) {
+		fn(change)
+	}
+	if c.bubble != nil {
+		c.bubble(change)
+	}
+}
+
+func (c *defaultDataContext) subscribe(fn func(Change)) func() {
+	cancel := c.watchers.add(false, fn)
+	notifier, ok := c.parent.(changeNotifier)
+	if !ok {
+		return cancel
+	}
+	// 父作用域的变更只有在本地没有同名键时才可见
+	cancelParent := notifier.subscribe(func(change Change) {
+		if !c.hasLocal(change.Key) {
+			fn(change)
+		}
+	})
+	return func() {
+		cancel()
+		cancelParent()
+	}
+}
+
+func (c *defaultDataContext) subscribeAll(fn func(Change)) func() {
+	return c.watchers.add(true, fn)
+}
+
+func (c *defaultDataContext) Wait(ctx context.Context, key string) (interface{}, error) {
+	ready := make(chan interface{}, 1)
+	// 先订阅再读取，避免在两者之间发生的写入被遗漏
+	cancel := c.subscribe(func(change Change) {
+		if change.Key != key || change.Deleted {
+			return
+		}
+		select {
+		case ready <- change.Value:
+		default:
+		}
+	})
+	defer cancel()
+
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+	select {
+	case v := <-ready:
+		return v, nil
+	case <-ctx.Done():
+		return nil, &DataContextError{
+			Type:    "wait_cancelled",
+			Key:     key,
+			Message: "context done before key was set",
+			Cause:   ctx.Err(),
+		}
+	}
+}
+
+func (c *defaultDataContext) Watch(ctx context.Context, key string) <-chan Change {
+	out := make(chan Change)
+	var (
+		mu     sync.Mutex
+		queue  []Change
+		signal = make(chan struct{}, 1)
+	)
+	// 监听器只入队，由单独的 goroutine 投递，写入方不会被消费者阻塞；队列不设上限，见 DataContext.Watch
+	cancel := c.subscribe(func(change Change) {
+		if key != "" && change.Key != key {
+			return
+		}
+		mu.Lock()
+		queue = append(queue, change)
+		mu.Unlock()
+		select {
+		case signal <- struct{}{}:
+		default:
+		}
+	})
+
+	go func() {
+		defer close(out)
+		defer cancel()
+		for {
+			mu.Lock()
+			if len(queue) == 0 {
+				mu.Unlock()
+				select {
+				case <-signal:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+			next := queue[0]
+			queue = queue[1:]
+			mu.Unlock()
+
+			select {
+			case out <- next:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// watchData 为执行期间的 DataChangeMiddleware 订阅 data 的所有写入，返回取消函数
+func watchData(ctx context.Context, data DataContext, middleware []Middleware) func() {
+	var observers []DataChangeMiddleware
+	for _, m := range middleware {
+		if observer, ok := m.(DataChangeMiddleware); ok {
+			observers = append(observers, observer)
+		}
+	}
+	notifier, ok := data.(changeNotifier)
+	if len(observers) == 0 || !ok {
+		return func() {}
+	}
+	return notifier.subscribeAll(func(change Change) {
+		for _, observer := range observers {
+			observer.OnDataChange(ctx, change)
+		}
+	})
+}
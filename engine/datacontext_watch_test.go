@@ -0,0 +1,191 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDataContextWait(t *testing.T) {
+	data := NewDataContextWith(map[string]interface{}{"ready": 1})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if v, err := data.Wait(ctx, "ready"); err != nil || v != 1 {
+		t.Errorf("Expected existing value, got %v %v", v, err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		data.Set("later", "value")
+	}()
+	if v, err := data.Wait(ctx, "later"); err != nil || v != "value" {
+		t.Errorf("Expected value set later, got %v %v", v, err)
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	_, err := data.Wait(short, "never")
+	var dcErr *DataContextError
+	if !errors.As(err, &dcErr) || dcErr.Type != "wait_cancelled" || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected wait_cancelled wrapping deadline exceeded, got %v", err)
+	}
+}
+
+func TestDataContextScopeWait(t *testing.T) {
+	root := NewDataContext()
+	scope := root.Scope("s")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		root.Set("shared", "from-root")
+	}()
+	if v, err := scope.Wait(ctx, "shared"); err != nil || v != "from-root" {
+		t.Errorf("Expected scope to observe parent write, got %v %v", v, err)
+	}
+}
+
+func TestDataContextWatch(t *testing.T) {
+	data := NewDataContext()
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := data.Watch(ctx, "counter")
+
+	// 写入方不会因为没有消费者而阻塞
+	for i := 0; i < 3; i++ {
+		if _, err := data.Incr("counter", 1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	data.Set("other", true)
+	data.Delete("counter")
+
+	var received []Change
+	for len(received) < 4 {
+		select {
+		case change := <-changes:
+			received = append(received, change)
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out, received %+v", received)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if received[i].Key != "counter" || received[i].Value != int64(i+1) || received[i].Existed != (i > 0) {
+			t.Errorf("Unexpected change %d: %+v", i, received[i])
+		}
+	}
+	if !received[3].Deleted || received[3].Old != int64(3) {
+		t.Errorf("Expected delete change, got %+v", received[3])
+	}
+
+	cancel()
+	select {
+	case _, ok := <-changes:
+		if ok {
+			t.Error("Expected no further changes after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected channel to be closed after cancel")
+	}
+}
+
+func TestDataContextWatchShadowedByScope(t *testing.T) {
+	root := NewDataContext()
+	scope := root.Scope("s")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := scope.Watch(ctx, "")
+
+	scope.Set("key", "local")
+	root.Set("key", "root") // 被本地值覆盖，不可见
+	root.Set("other", 1)
+
+	first, second := <-changes, <-changes
+	if first.Key != "key" || first.Value != "local" || first.Scope != "s" {
+		t.Errorf("Unexpected first change: %+v", first)
+	}
+	if second.Key != "other" || second.Scope != "" {
+		t.Errorf("Expected shadowed root write to be skipped, got %+v", second)
+	}
+}
+
+// MockDataChangeMiddleware 记录执行期间的数据变更
+type MockDataChangeMiddleware struct {
+	MockMiddleware
+	mu      sync.Mutex
+	changes []Change
+}
+
+func (m *MockDataChangeMiddleware) OnDataChange(ctx context.Context, change Change) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.changes = append(m.changes, change)
+}
+
+func TestEngineAsyncLayerWithWait(t *testing.T) {
+	config := &Config{
+		Name: "async",
+		Layers: []LayerConfig{
+			{Name: "background", Mode: AsyncMode, Timeout: time.Second, Components: []ComponentConfig{{Name: "slow", Type: "slow"}}},
+			{Name: "consume", Mode: SerialMode, Components: []ComponentConfig{{Name: "waiter", Type: "waiter"}}},
+		},
+	}
+
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "slow",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				select {
+				case <-time.After(20 * time.Millisecond):
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				data.Set("slow_result", "done")
+				return data.Export("slow_result")
+			}}, nil
+		},
+	})
+	registry.Register(&MockComponentFactory{
+		componentType: "waiter",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				v, err := data.Wait(ctx, "slow_result")
+				if err != nil {
+					return err
+				}
+				data.Set("consumed", v)
+				return nil
+			}}, nil
+		},
+	})
+
+	middleware := &MockDataChangeMiddleware{}
+	engine, err := NewEngine(config, registry, WithDataScopes(), WithMiddleware(middleware), WithLogger(&MockLogger{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data := NewDataContext()
+	if _, err := engine.Execute(context.Background(), data); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	middleware.mu.Lock()
+	defer middleware.mu.Unlock()
+	want := []struct{ key, scope string }{
+		{"slow_result", "background.slow"},
+		{"slow_result", ""}, // Export
+		{"consumed", "consume.waiter"},
+	}
+	if len(middleware.changes) != len(want) {
+		t.Fatalf("Expected %d observed changes, got %+v", len(want), middleware.changes)
+	}
+	for i, w := range want {
+		if got := middleware.changes[i]; got.Key != w.key || got.Scope != w.scope {
+			t.Errorf("Change %d: expected %s in %q, got %+v", i, w.key, w.scope, got)
+		}
+	}
+}
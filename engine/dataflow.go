@@ -0,0 +1,249 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DataFlowComponent 可选接口：组件在代码中声明读取与写入的 DataContext 键
+// 与 ComponentConfig 的 inputs/outputs 合并后参与数据流校验（见 NewEngine）
+type DataFlowComponent interface {
+	Component
+
+	// Inputs 返回组件读取的键
+	Inputs() []string
+
+	// Outputs 返回组件写入的键
+	Outputs() []string
+}
+
+// DataFlow 根据组件声明的 inputs/outputs 分析得到的数据流
+// 推导出的依赖只在这里提供，不会写入配置中用户填写的 dependencies 字段
+// 依赖仅供工具展示与参考，引擎不会据此调度：层按配置顺序执行，异步层不会被等待
+type DataFlow struct {
+	// Producers 键 -> 写入该键的组件（"层名.组件名"），按执行顺序排列
+	Producers map[string][]string `json:"producers"`
+	// Dependencies 组件（"层名.组件名"）-> 其输入的上游生产者组件，已排序去重
+	// 异步层中的生产者不计入：后续层开始时它们可能仍在运行，读取方需要自行等待（如 DataContext.Wait）
+	Dependencies map[string][]string `json:"dependencies"`
+	// LayerDependencies 层名 -> 产生其组件输入的其他层，已排序去重
+	LayerDependencies map[string][]string `json:"layer_dependencies"`
+}
+
+// AnalyzeDataFlow 分析配置中组件声明的数据流，禁用的层与组件不参与分析
+// 以下情况以 ValidationErrors 一次性报告：
+//   - 输入既不是工作流输入（Config.Inputs），也没有由之前的层或串行层中之前的组件产生
+//   - 并行/异步层中两个组件写入同一个键
+func AnalyzeDataFlow(config *Config) (*DataFlow, error) {
+	flow, errs := analyzeDataFlow(config)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return flow, nil
+}
+
+// dataProducer 数据流中的一个生产者
+type dataProducer struct {
+	ref   string // 层名.组件名
+	layer int
+	index int
+}
+
+// analyzeDataFlow 分析数据流并收集所有错误
+func analyzeDataFlow(config *Config) (*DataFlow, ValidationErrors) {
+	flow := &DataFlow{
+		Producers:         make(map[string][]string),
+		Dependencies:      make(map[string][]string),
+		LayerDependencies: make(map[string][]string),
+	}
+
+	// 先收集所有生产者，便于在报错时指出键由谁产生
+	all := make(map[string][]dataProducer)
+	for i, layer := range config.Layers {
+		if !layer.IsEnabled() {
+			continue
+		}
+		for j, component := range layer.Components {
+			if !component.IsEnabled() {
+				continue
+			}
+			for _, key := range uniqueKeys(component.Outputs) {
+				all[key] = append(all[key], dataProducer{ref: ComponentScopeName(layer.Name, component.Name), layer: i, index: j})
+			}
+		}
+	}
+
+	workflowInputs := make(map[string]bool, len(config.Inputs))
+	for _, input := range config.Inputs {
+		workflowInputs[input.Name] = true
+	}
+
+	var errs ValidationErrors
+	for i, layer := range config.Layers {
+		if !layer.IsEnabled() {
+			continue
+		}
+		concurrent := layer.Mode == ParallelMode || layer.Mode == AsyncMode
+		writers := make(map[string]string)
+		var layerDeps []string
+
+		for j, component := range layer.Components {
+			if !component.IsEnabled() {
+				continue
+			}
+			ref := ComponentScopeName(layer.Name, component.Name)
+
+			var deps []string
+			for _, key := range uniqueKeys(component.Inputs) {
+				if workflowInputs[key] {
+					continue
+				}
+				upstream, conflict := upstreamProducers(all[key], i, j, concurrent)
+				if len(upstream) > 0 {
+					for _, producer := range upstream {
+						if producer.layer != i && config.Layers[producer.layer].Mode == AsyncMode {
+							continue
+						}
+						deps = append(deps, producer.ref)
+						if producer.layer != i {
+							layerDeps = append(layerDeps, config.Layers[producer.layer].Name)
+						}
+					}
+					continue
+				}
+				message := fmt.Sprintf("input %s is not produced by any upstream component or declared workflow input", key)
+				if conflict != "" {
+					message = fmt.Sprintf("input %s is produced by %s, which does not run before this component", key, conflict)
+				}
+				errs = append(errs, &ValidationError{
+					Field:   fmt.Sprintf("layers[%d].components[%d].inputs", i, j),
+					Value:   key,
+					Message: message,
+				})
+			}
+			if len(deps) > 0 {
+				flow.Dependencies[ref] = uniqueSorted(deps)
+			}
+
+			for _, key := range uniqueKeys(component.Outputs) {
+				if other, ok := writers[key]; ok && concurrent {
+					errs = append(errs, &ValidationError{
+						Field:   fmt.Sprintf("layers[%d].components[%d].outputs", i, j),
+						Value:   key,
+						Message: fmt.Sprintf("output %s is also written by %s, which runs concurrently in the same %s layer", key, other, layer.Mode),
+					})
+				}
+				writers[key] = ref
+			}
+		}
+		if len(layerDeps) > 0 {
+			flow.LayerDependencies[layer.Name] = uniqueSorted(layerDeps)
+		}
+	}
+
+	for key, producers := range all {
+		for _, producer := range producers {
+			flow.Producers[key] = append(flow.Producers[key], producer.ref)
+		}
+	}
+	return flow, errs
+}
+
+// upstreamProducers 返回在 (layer, index) 之前运行的生产者；
+// 若没有，返回第一个存在但不在之前运行的生产者用于报错
+func upstreamProducers(producers []dataProducer, layer, index int, concurrent bool) ([]dataProducer, string) {
+	var upstream []dataProducer
+	conflict := ""
+	for _, producer := range producers {
+		before := producer.layer < layer || (producer.layer == layer && !concurrent && producer.index < index)
+		if before {
+			upstream = append(upstream, producer)
+		} else if conflict == "" && !(producer.layer == layer && producer.index == index) {
+			conflict = producer.ref
+		}
+	}
+	return upstream, conflict
+}
+
+// validateDataFlow 解析时校验组件声明的数据流
+func (p *ConfigParser) validateDataFlow(config *Config) error {
+	if _, errs := analyzeDataFlow(config); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// mergeDependencies 追加尚不存在的依赖，保留原有顺序
+func mergeDependencies(existing, extra []string) []string {
+	seen := make(map[string]bool, len(existing))
+	for _, dep := range existing {
+		seen[dep] = true
+	}
+	for _, dep := range extra {
+		if !seen[dep] {
+			existing = append(existing, dep)
+			seen[dep] = true
+		}
+	}
+	return existing
+}
+
+// uniqueKeys 去除重复与空白的键，保留原有顺序
+func uniqueKeys(keys []string) []string {
+	seen := make(map[string]bool, len(keys))
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, key)
+	}
+	return result
+}
+
+// uniqueSorted 去重并排序
+func uniqueSorted(values []string) []string {
+	result := uniqueKeys(values)
+	sort.Strings(result)
+	return result
+}
+
+// dataFlowConfig 返回合并了组件实例 DataFlowComponent 声明的配置副本，
+// 没有任何组件实现该接口时返回原配置
+func dataFlowConfig(config *Config, layers []*Layer) *Config {
+	declared := false
+	for _, layer := range layers {
+		for _, component := range layer.components {
+			if _, ok := component.(DataFlowComponent); ok {
+				declared = true
+			}
+		}
+	}
+	if !declared {
+		return config
+	}
+
+	copied := *config
+	copied.Layers = make([]LayerConfig, len(config.Layers))
+	for i, layerConfig := range config.Layers {
+		layerConfig.Components = append([]ComponentConfig(nil), layerConfig.Components...)
+		if i < len(layers) {
+			for _, component := range layers[i].components {
+				flowComponent, ok := component.(DataFlowComponent)
+				if !ok {
+					continue
+				}
+				for j := range layerConfig.Components {
+					cc := &layerConfig.Components[j]
+					if cc.Name == component.Name() {
+						cc.Inputs = mergeDependencies(append([]string(nil), cc.Inputs...), flowComponent.Inputs())
+						cc.Outputs = mergeDependencies(append([]string(nil), cc.Outputs...), flowComponent.Outputs())
+					}
+				}
+			}
+		}
+		copied.Layers[i] = layerConfig
+	}
+	return &copied
+}
@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigDataFlowInference(t *testing.T) {
+	cfg, err := NewConfigParser().ParseBytes([]byte(`{
+		"name": "flow",
+		"inputs": [{"name": "input_path", "required": true}],
+		"layers": [
+			{"name": "read", "components": [
+				{"name": "reader", "type": "X", "inputs": ["input_path"], "outputs": ["file_data"]},
+				{"name": "parser", "type": "X", "inputs": ["file_data"], "outputs": ["records"]}
+			]},
+			{"name": "process", "mode": "parallel", "components": [
+				{"name": "stats", "type": "X", "inputs": ["records"], "outputs": ["stats"]},
+				{"name": "index", "type": "X", "inputs": ["records", "file_data"], "outputs": ["index"]}
+			]},
+			{"name": "write", "components": [
+				{"name": "writer", "type": "X", "inputs": ["stats", "index"]}
+			]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 推导出的依赖不写入用户配置
+	if deps := findComponent(findLayer(cfg, "process"), "index").Dependencies; len(deps) != 0 {
+		t.Errorf("Expected parsed component dependencies to stay as written, got %v", deps)
+	}
+	if deps := findLayer(cfg, "write").Dependencies; len(deps) != 0 {
+		t.Errorf("Expected parsed layer dependencies to stay as written, got %v", deps)
+	}
+
+	flow, err := AnalyzeDataFlow(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := []string{"read.parser", "read.reader"}; !reflect.DeepEqual(flow.Dependencies["process.index"], want) {
+		t.Errorf("Expected inferred component dependencies %v, got %v", want, flow.Dependencies["process.index"])
+	}
+	if want := []string{"read.reader"}; !reflect.DeepEqual(flow.Dependencies["read.parser"], want) {
+		t.Errorf("Expected same-layer dependency %v, got %v", want, flow.Dependencies["read.parser"])
+	}
+	if want := []string{"process"}; !reflect.DeepEqual(flow.LayerDependencies["write"], want) {
+		t.Errorf("Expected inferred layer dependencies %v, got %v", want, flow.LayerDependencies["write"])
+	}
+	if want := []string{"read.reader"}; !reflect.DeepEqual(flow.Producers["file_data"], want) {
+		t.Errorf("Unexpected producers: %v", flow.Producers)
+	}
+}
+
+func TestConfigDataFlowAsyncProducers(t *testing.T) {
+	cfg, err := NewConfigParser().ParseBytes([]byte(`{
+		"name": "flow",
+		"layers": [
+			{"name": "load", "components": [
+				{"name": "loader", "type": "X", "outputs": ["data"]}
+			]},
+			{"name": "audit", "mode": "async", "components": [
+				{"name": "auditor", "type": "X", "inputs": ["data"], "outputs": ["audit_log"]}
+			]},
+			{"name": "report", "components": [
+				{"name": "reporter", "type": "X", "inputs": ["data", "audit_log"]}
+			]}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("Expected outputs of async layers to satisfy inputs, got %v", err)
+	}
+
+	flow, err := AnalyzeDataFlow(cfg)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// 异步层返回时组件仍在运行，不能作为后续组件的依赖
+	if want := []string{"load.loader"}; !reflect.DeepEqual(flow.Dependencies["report.reporter"], want) {
+		t.Errorf("Expected async producers to be excluded, got %v", flow.Dependencies["report.reporter"])
+	}
+	if want := []string{"load"}; !reflect.DeepEqual(flow.LayerDependencies["report"], want) {
+		t.Errorf("Expected async layer to be excluded from layer dependencies, got %v", flow.LayerDependencies["report"])
+	}
+	if want := []string{"load.loader"}; !reflect.DeepEqual(flow.Dependencies["audit.auditor"], want) {
+		t.Errorf("Expected async consumers to keep their dependencies, got %v", flow.Dependencies["audit.auditor"])
+	}
+}
+
+func TestConfigDataFlowErrors(t *testing.T) {
+	_, err := NewConfigParser().ParseBytes([]byte(`{
+		"name": "flow",
+		"layers": [
+			{"name": "first", "components": [
+				{"name": "a", "type": "X", "inputs": ["later"]},
+				{"name": "b", "type": "X", "inputs": ["missing"]},
+				{"name": "c", "type": "X", "outputs": ["later"]}
+			]},
+			{"name": "fan_out", "mode": "parallel", "components": [
+				{"name": "p1", "type": "X", "outputs": ["result"]},
+				{"name": "p2", "type": "X", "outputs": ["result"], "inputs": ["sibling"]},
+				{"name": "p3", "type": "X", "outputs": ["sibling"]}
+			]},
+			{"name": "disabled", "enabled": false, "components": [
+				{"name": "d", "type": "X", "inputs": ["nobody"]}
+			]}
+		]
+	}`))
+	errs, ok := err.(ValidationErrors)
+	if !ok || len(errs) != 4 {
+		t.Fatalf("Expected 4 validation errors, got %v", err)
+	}
+	checks := []struct{ field, contains string }{
+		{"layers[0].components[0].inputs", "produced by first.c"},
+		{"layers[0].components[1].inputs", "not produced by any upstream"},
+		{"layers[1].components[1].inputs", "produced by fan_out.p3"},
+		{"layers[1].components[1].outputs", "also written by fan_out.p1"},
+	}
+	for i, check := range checks {
+		if errs[i].Field != check.field || !strings.Contains(errs[i].Message, check.contains) {
+			t.Errorf("error %d: expected %s containing %q, got %s: %s", i, check.field, check.contains, errs[i].Field, errs[i].Message)
+		}
+	}
+}
+
+// MockDataFlowComponent 在代码中声明输入输出的组件
+type MockDataFlowComponent struct {
+	MockComponent
+	inputs, outputs []string
+}
+
+func (m *MockDataFlowComponent) Inputs() []string  { return m.inputs }
+func (m *MockDataFlowComponent) Outputs() []string { return m.outputs }
+
+func TestEngineDataFlowComponent(t *testing.T) {
+	config := &Config{
+		Name: "flow",
+		Layers: []LayerConfig{
+			{Name: "produce", Mode: ParallelMode, Components: []ComponentConfig{
+				{Name: "a", Type: "flow", Outputs: []string{"result"}},
+				{Name: "b", Type: "flow"},
+			}},
+		},
+	}
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "flow",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockDataFlowComponent{MockComponent: MockComponent{name: config.Name}, outputs: []string{"result"}}, nil
+		},
+	})
+
+	_, err := NewEngine(config, registry)
+	if err == nil || !strings.Contains(err.Error(), "also written by produce.a") {
+		t.Fatalf("Expected parallel write conflict, got %v", err)
+	}
+
+	config.Layers[0].Mode = SerialMode
+	engine, err := NewEngine(config, registry, WithLogger(&MockLogger{}))
+	if err != nil {
+		t.Fatalf("Expected serial writers to be allowed, got %v", err)
+	}
+	if _, err := engine.Execute(context.Background(), NewDataContext()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if config.Layers[0].Components[1].Outputs != nil {
+		t.Error("NewEngine must not modify the caller's config")
+	}
+}
@@ -0,0 +1,400 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ConfigChangeType 配置变更类型
+type ConfigChangeType string
+
+const (
+	ConfigAdded    ConfigChangeType = "added"    // 新增层、组件、字段或数组元素
+	ConfigRemoved  ConfigChangeType = "removed"  // 删除层、组件、字段或数组元素
+	ConfigModified ConfigChangeType = "modified" // 字段值改变
+	ConfigMoved    ConfigChangeType = "moved"    // 按名称标识的元素（层、组件、输入）顺序改变
+)
+
+// ConfigChange 单条配置变更
+// Path 为 JSON 路径；带 name 字段的对象数组（layers、components、inputs 等）按名称定位，
+// 例如 layers[notify].components[email].config.channel，其余数组按下标定位
+// moved 变更的 Old/New 为元素在数组中的旧/新位置
+type ConfigChange struct {
+	Type ConfigChangeType `json:"type"`
+	Path string           `json:"path"`
+	Old  interface{}      `json:"old"`
+	New  interface{}      `json:"new"`
+}
+
+// String 返回可读的变更描述
+func (c ConfigChange) String() string {
+	switch c.Type {
+	case ConfigAdded:
+		return fmt.Sprintf("+ %s: %s", c.Path, diffValueString(c.New))
+	case ConfigRemoved:
+		return fmt.Sprintf("- %s: %s", c.Path, diffValueString(c.Old))
+	case ConfigMoved:
+		return fmt.Sprintf("> %s: moved from %v to %v", c.Path, c.Old, c.New)
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", c.Path, diffValueString(c.Old), diffValueString(c.New))
+}
+
+// ConfigDiff 两份配置之间的语义差异
+type ConfigDiff struct {
+	Changes []ConfigChange `json:"changes"`
+}
+
+// Empty 判断两份配置是否语义等价
+func (d *ConfigDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// String 每行输出一条变更
+func (d *ConfigDiff) String() string {
+	lines := make([]string, len(d.Changes))
+	for i, change := range d.Changes {
+		lines[i] = change.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// DiffConfigs 比较两份配置的语义差异
+// 比较前两份配置都会按 setDefaults 补全默认值，并将未设置的 enabled/critical 视为 true/false，
+// 因此省略默认值与显式写出默认值不会产生差异；时长按时长值比较（"60s" 与 "1m" 等价）
+func DiffConfigs(oldConfig, newConfig *Config) (*ConfigDiff, error) {
+	oldTree, err := normalizedConfigTree(oldConfig)
+	if err != nil {
+		return nil, err
+	}
+	newTree, err := normalizedConfigTree(newConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &ConfigDiff{Changes: []ConfigChange{}}
+	diffValues("", oldTree, newTree, &diff.Changes)
+	return diff, nil
+}
+
+// DiffFiles 使用当前解析器（相同的 profile、变量来源等）解析两个配置文件并比较，
+// 比较的是 extends 完全合并后的结果
+func (p *ConfigParser) DiffFiles(oldPath, newPath string) (*ConfigDiff, error) {
+	oldConfig, err := p.ParseFile(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newConfig, err := p.ParseFile(newPath)
+	if err != nil {
+		return nil, err
+	}
+	return DiffConfigs(oldConfig, newConfig)
+}
+
+// DiffExtends 比较配置文件与其 extends 父配置合并结果之间的差异，
+// 即子配置的覆盖实际改变了什么；父配置不按完整工作流校验。配置没有 extends 时返回错误
+func (p *ConfigParser) DiffExtends(filePath string) (*ConfigDiff, error) {
+	state := newParseState(nil)
+	data, err := state.readFile(filePath)
+	if err != nil {
+		return nil, &ConfigError{
+			Type:    "file_open_failed",
+			Message: fmt.Sprintf("failed to open config file: %v", err),
+			Cause:   err,
+		}
+	}
+
+	// 与 loadBytes 相同，extends 中的引用先经过变量插值
+	raw, err := p.interpolateJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		Extends ExtendsList `json:"extends"`
+	}
+	if obj, ok := raw.(map[string]interface{}); ok && obj["extends"] != nil {
+		encoded, _ := json.Marshal(obj["extends"])
+		if err := json.Unmarshal(encoded, &header.Extends); err != nil {
+			return nil, &ConfigError{
+				Type:    "json_unmarshal_failed",
+				Message: fmt.Sprintf("failed to unmarshal JSON config: %v", err),
+				Cause:   err,
+			}
+		}
+	}
+	if len(header.Extends) == 0 {
+		return nil, &ConfigError{
+			Type:    "no_extends",
+			Field:   "extends",
+			Message: fmt.Sprintf("config %s does not extend any parent", filePath),
+		}
+	}
+
+	// 按顺序合并所有父配置，得到子配置覆盖前的基线
+	state.visiting[state.key(filePath)] = true
+	var parent *Config
+	for _, ref := range header.Extends {
+		loaded, err := p.loadFile(state, state.resolve(state.dir(filePath), ref))
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			parent = loaded
+			continue
+		}
+		if parent, err = p.mergeConfigs(parent, loaded); err != nil {
+			return nil, err
+		}
+	}
+	// 父配置通常只用于被继承（如没有 name），因此只应用 profile 并填充默认值，不按完整工作流校验；
+	// 所选 profile 只在子配置中定义时，父配置保持不变
+	if effective, err := p.selectProfile(parent); err == nil {
+		parent = effective
+	} else if configErr, ok := err.(*ConfigError); !ok || configErr.Type != "profile_not_found" {
+		return nil, err
+	}
+	p.setDefaults(parent)
+
+	child, err := p.ParseFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return DiffConfigs(parent, child)
+}
+
+// normalizedConfigTree 将配置补全默认值后转换为通用 JSON 结构
+func normalizedConfigTree(config *Config) (interface{}, error) {
+	if config == nil {
+		return nil, &ConfigError{
+			Type:    "nil_config",
+			Message: "config cannot be nil",
+		}
+	}
+
+	copied, err := config.deepCopy()
+	if err != nil {
+		return nil, err
+	}
+	NewConfigParser().setDefaults(copied)
+	for i := range copied.Layers {
+		layer := &copied.Layers[i]
+		layer.Enabled = BoolPtr(layer.IsEnabled())
+		for j := range layer.Components {
+			component := &layer.Components[j]
+			component.Enabled = BoolPtr(component.IsEnabled())
+			component.Critical = BoolPtr(component.IsCritical())
+		}
+	}
+	// 合并结果中不再需要 extends
+	copied.Extends = nil
+
+	data, err := json.Marshal(copied)
+	if err != nil {
+		return nil, &ConfigError{
+			Type:    "json_marshal_failed",
+			Message: fmt.Sprintf("failed to marshal config to JSON: %v", err),
+			Cause:   err,
+		}
+	}
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, &ConfigError{
+			Type:    "json_unmarshal_failed",
+			Message: fmt.Sprintf("failed to unmarshal JSON config: %v", err),
+			Cause:   err,
+		}
+	}
+	return pruneNulls(tree), nil
+}
+
+// pruneNulls 删除对象中的 null 字段，使未设置与显式 null 等价
+func pruneNulls(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if item == nil {
+				delete(v, k)
+				continue
+			}
+			v[k] = pruneNulls(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = pruneNulls(item)
+		}
+	}
+	return value
+}
+
+// diffValues 递归比较两个 JSON 值并记录变更
+func diffValues(path string, oldValue, newValue interface{}, changes *[]ConfigChange) {
+	switch o := oldValue.(type) {
+	case map[string]interface{}:
+		if n, ok := newValue.(map[string]interface{}); ok {
+			diffObjects(path, o, n, changes)
+			return
+		}
+	case []interface{}:
+		if n, ok := newValue.([]interface{}); ok {
+			if namedElements(o) && namedElements(n) {
+				diffNamedArrays(path, o, n, changes)
+			} else {
+				diffArrays(path, o, n, changes)
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(oldValue, newValue) {
+		*changes = append(*changes, ConfigChange{Type: ConfigModified, Path: path, Old: oldValue, New: newValue})
+	}
+}
+
+// diffObjects 按键比较对象，键按字典序遍历以保证输出稳定
+func diffObjects(path string, oldObj, newObj map[string]interface{}, changes *[]ConfigChange) {
+	keys := make([]string, 0, len(oldObj)+len(newObj))
+	for k := range oldObj {
+		keys = append(keys, k)
+	}
+	for k := range newObj {
+		if _, ok := oldObj[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fieldPath := joinFieldPath(path, k)
+		oldValue, inOld := oldObj[k]
+		newValue, inNew := newObj[k]
+		switch {
+		case !inNew:
+			*changes = append(*changes, ConfigChange{Type: ConfigRemoved, Path: fieldPath, Old: oldValue})
+		case !inOld:
+			*changes = append(*changes, ConfigChange{Type: ConfigAdded, Path: fieldPath, New: newValue})
+		default:
+			diffValues(fieldPath, oldValue, newValue, changes)
+		}
+	}
+}
+
+// diffArrays 按下标比较普通数组
+func diffArrays(path string, oldList, newList []interface{}, changes *[]ConfigChange) {
+	for i := 0; i < len(oldList) || i < len(newList); i++ {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(newList):
+			*changes = append(*changes, ConfigChange{Type: ConfigRemoved, Path: itemPath, Old: oldList[i]})
+		case i >= len(oldList):
+			*changes = append(*changes, ConfigChange{Type: ConfigAdded, Path: itemPath, New: newList[i]})
+		default:
+			diffValues(itemPath, oldList[i], newList[i], changes)
+		}
+	}
+}
+
+// diffNamedArrays 按 name 比较对象数组：先报告删除，再按新顺序报告新增、移动与字段变更
+func diffNamedArrays(path string, oldList, newList []interface{}, changes *[]ConfigChange) {
+	oldIdx := make(map[string]int, len(oldList))
+	for i, item := range oldList {
+		oldIdx[elementName(item)] = i
+	}
+	newIdx := make(map[string]int, len(newList))
+	for i, item := range newList {
+		newIdx[elementName(item)] = i
+	}
+
+	for _, item := range oldList {
+		name := elementName(item)
+		if _, ok := newIdx[name]; !ok {
+			*changes = append(*changes, ConfigChange{Type: ConfigRemoved, Path: namedPath(path, name), Old: item})
+		}
+	}
+
+	// 两侧都存在的元素中，不在最长有序子序列上的视为移动
+	var common []string
+	for _, item := range newList {
+		if _, ok := oldIdx[elementName(item)]; ok {
+			common = append(common, elementName(item))
+		}
+	}
+	stable := stableOrder(common, oldIdx)
+
+	for i, item := range newList {
+		name := elementName(item)
+		itemPath := namedPath(path, name)
+		j, ok := oldIdx[name]
+		if !ok {
+			*changes = append(*changes, ConfigChange{Type: ConfigAdded, Path: itemPath, New: item})
+			continue
+		}
+		if !stable[name] {
+			*changes = append(*changes, ConfigChange{Type: ConfigMoved, Path: itemPath, Old: j, New: i})
+		}
+		diffValues(itemPath, oldList[j], item, changes)
+	}
+}
+
+// stableOrder 返回保持原有相对顺序的最大元素集合（按旧位置的最长递增子序列）
+func stableOrder(names []string, oldIdx map[string]int) map[string]bool {
+	n := len(names)
+	length := make([]int, n)
+	prev := make([]int, n)
+	best := -1
+	for i := range names {
+		length[i], prev[i] = 1, -1
+		for j := 0; j < i; j++ {
+			if oldIdx[names[j]] < oldIdx[names[i]] && length[j]+1 > length[i] {
+				length[i], prev[i] = length[j]+1, j
+			}
+		}
+		if best < 0 || length[i] > length[best] {
+			best = i
+		}
+	}
+
+	stable := make(map[string]bool, n)
+	for i := best; i >= 0; i = prev[i] {
+		stable[names[i]] = true
+	}
+	return stable
+}
+
+// namedElements 判断数组元素是否都是带唯一非空 name 字段的对象
+func namedElements(list []interface{}) bool {
+	seen := make(map[string]bool, len(list))
+	for _, item := range list {
+		name := elementName(item)
+		if name == "" || seen[name] {
+			return false
+		}
+		seen[name] = true
+	}
+	return true
+}
+
+// elementName 返回对象的 name 字段
+func elementName(item interface{}) string {
+	if obj, ok := item.(map[string]interface{}); ok {
+		if name, ok := obj["name"].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// namedPath 按名称定位数组元素的路径
+func namedPath(path, name string) string {
+	return fmt.Sprintf("%s[%s]", path, name)
+}
+
+// diffValueString 以 JSON 形式输出变更值
+func diffValueString(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func parseDiffConfig(t *testing.T, data string) *Config {
+	t.Helper()
+	cfg, err := NewConfigParser().ParseBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	return cfg
+}
+
+func TestDiffConfigsIgnoresDefaults(t *testing.T) {
+	implicit := parseDiffConfig(t, `{"name": "wf", "layers": [
+		{"name": "L1", "components": [{"name": "C1", "type": "X"}]}
+	]}`)
+	explicit := parseDiffConfig(t, `{"name": "wf", "version": "1.0.0", "layers": [
+		{"name": "L1", "mode": "serial", "enabled": true, "components": [
+			{"name": "C1", "type": "X", "timeout": "30s", "enabled": true, "critical": false}
+		]}
+	]}`)
+
+	diff, err := DiffConfigs(implicit, explicit)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !diff.Empty() {
+		t.Errorf("Expected no changes, got:\n%s", diff)
+	}
+
+	// 未经解析器补全默认值的配置同样适用
+	raw := &Config{Name: "wf", Layers: []LayerConfig{{Name: "L1", Components: []ComponentConfig{{Name: "C1", Type: "X"}}}}}
+	if diff, _ := DiffConfigs(raw, explicit); !diff.Empty() {
+		t.Errorf("Expected no changes for unnormalized config, got:\n%s", diff)
+	}
+}
+
+func TestDiffConfigsStructuralChanges(t *testing.T) {
+	oldConfig := parseDiffConfig(t, `{"name": "wf", "layers": [
+		{"name": "A", "components": [{"name": "a1", "type": "X"}, {"name": "a2", "type": "X"}]},
+		{"name": "B", "components": [{"name": "b1", "type": "X", "config": {"channel": "slack", "tags": ["x"]}}]},
+		{"name": "C", "components": [{"name": "c1", "type": "X"}]}
+	]}`)
+	newConfig := parseDiffConfig(t, `{"name": "wf", "layers": [
+		{"name": "B", "components": [{"name": "b1", "type": "X", "timeout": "1m", "config": {"channel": "email", "tags": ["x", "y"]}}]},
+		{"name": "A", "components": [{"name": "a2", "type": "X"}, {"name": "a3", "type": "X", "critical": true}]},
+		{"name": "D", "components": [{"name": "d1", "type": "X"}]}
+	]}`)
+
+	diff, err := DiffConfigs(oldConfig, newConfig)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := []string{
+		`- layers[C]`,
+		`~ layers[B].components[b1].config.channel: "slack" -> "email"`,
+		`+ layers[B].components[b1].config.tags[1]: "y"`,
+		`~ layers[B].components[b1].timeout: "30s" -> "1m0s"`,
+		`> layers[A]: moved from 0 to 1`,
+		`- layers[A].components[a1]`,
+		`+ layers[A].components[a3]`,
+		`+ layers[D]`,
+	}
+	lines := strings.Split(diff.String(), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d changes, got %d:\n%s", len(want), len(lines), diff)
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("change %d: expected prefix %q, got %q", i, prefix, lines[i])
+		}
+	}
+}
+
+func TestDiffExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "base.json", `{"name": "base", "layers": [
+		{"name": "L1", "components": [{"name": "C1", "type": "X", "timeout": "10s"}, {"name": "C2", "type": "X"}]}
+	]}`)
+	child := writeFile(t, dir, "child.json", `{"extends": "base.json", "name": "child", "layers": [
+		{"name": "L1", "components": [{"name": "C1", "timeout": "10s"}, {"name": "C2", "remove": true}]}
+	]}`)
+	writeFile(t, dir, "plain.json", `{"name": "plain", "layers": [{"name": "L1", "components": [{"name": "C1", "type": "X"}]}]}`)
+
+	parser := NewConfigParser()
+	diff, err := parser.DiffExtends(child)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// C1 的 timeout 覆盖与父配置相同，不产生差异
+	want := []string{`- layers[L1].components[C2]`, `~ name: "base" -> "child"`}
+	lines := strings.Split(diff.String(), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d changes, got:\n%s", len(want), diff)
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("change %d: expected prefix %q, got %q", i, prefix, lines[i])
+		}
+	}
+
+	if _, err := parser.DiffExtends(dir + "/plain.json"); err == nil {
+		t.Error("Expected error for config without extends")
+	}
+
+	files, err := parser.DiffFiles(dir+"/plain.json", child)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if files.Empty() {
+		t.Error("Expected differences between plain and child configs")
+	}
+}
+
+func TestDiffExtendsPartialBase(t *testing.T) {
+	dir := t.TempDir()
+	// 只用于被继承的父配置没有 name
+	writeFile(t, dir, "base.json", `{"layers": [{"name": "L1", "components": [{"name": "C1", "type": "X"}]}]}`)
+	child := writeFile(t, dir, "child.json", `{"extends": "${BASE}.json", "name": "wf"}`)
+
+	parser := NewConfigParser(WithVariableSource(MapSource(map[string]string{"BASE": "base"})))
+	if _, err := parser.ParseFile(child); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	diff, err := parser.DiffExtends(child)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lines := strings.Split(diff.String(), "\n"); len(lines) != 1 || !strings.HasPrefix(lines[0], `~ name: "" -> "wf"`) {
+		t.Errorf("Expected only the name change, got:\n%s", diff)
+	}
+}
@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// jsonDuration 是 time.Duration 在 JSON 中的表示
+// 反序列化时同时接受 Go 时长字符串（如 "5s"、"1m30s"）与整数纳秒（兼容旧配置）
+// 序列化时输出可读的时长字符串
+type jsonDuration time.Duration
+
+// MarshalJSON 输出可读的时长字符串
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON 解析时长字符串或整数纳秒
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			*d = 0
+			return nil
+		}
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		*d = jsonDuration(parsed)
+		return nil
+	}
+
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid duration %s: must be a duration string or integer nanoseconds", string(data))
+	}
+	*d = jsonDuration(n)
+	return nil
+}
+
+// MarshalJSON 将时长字段输出为可读字符串
+func (c Config) MarshalJSON() ([]byte, error) {
+	type alias Config
+	return json.Marshal(struct {
+		alias
+		Timeout jsonDuration `json:"timeout,omitempty"`
+	}{alias: alias(c), Timeout: jsonDuration(c.Timeout)})
+}
+
+// UnmarshalJSON 允许时长字段使用字符串或整数纳秒
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type alias Config
+	aux := struct {
+		*alias
+		Timeout jsonDuration `json:"timeout,omitempty"`
+	}{alias: (*alias)(c), Timeout: jsonDuration(c.Timeout)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.Timeout = time.Duration(aux.Timeout)
+	return nil
+}
+
+// MarshalJSON 将时长字段输出为可读字符串
+func (l LayerConfig) MarshalJSON() ([]byte, error) {
+	type alias LayerConfig
+	return json.Marshal(struct {
+		alias
+		Timeout jsonDuration `json:"timeout"`
+	}{alias: alias(l), Timeout: jsonDuration(l.Timeout)})
+}
+
+// UnmarshalJSON 允许时长字段使用字符串或整数纳秒
+func (l *LayerConfig) UnmarshalJSON(data []byte) error {
+	type alias LayerConfig
+	aux := struct {
+		*alias
+		Timeout jsonDuration `json:"timeout"`
+	}{alias: (*alias)(l), Timeout: jsonDuration(l.Timeout)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	l.Timeout = time.Duration(aux.Timeout)
+	return nil
+}
+
+// MarshalJSON 将时长字段输出为可读字符串
+func (c ComponentConfig) MarshalJSON() ([]byte, error) {
+	type alias ComponentConfig
+	return json.Marshal(struct {
+		alias
+		Timeout jsonDuration `json:"timeout"`
+	}{alias: alias(c), Timeout: jsonDuration(c.Timeout)})
+}
+
+// UnmarshalJSON 允许时长字段使用字符串或整数纳秒
+func (c *ComponentConfig) UnmarshalJSON(data []byte) error {
+	type alias ComponentConfig
+	aux := struct {
+		*alias
+		Timeout jsonDuration `json:"timeout"`
+	}{alias: (*alias)(c), Timeout: jsonDuration(c.Timeout)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.Timeout = time.Duration(aux.Timeout)
+	return nil
+}
+
+// MarshalJSON 将时长字段输出为可读字符串
+func (r RetryConfig) MarshalJSON() ([]byte, error) {
+	type alias RetryConfig
+	return json.Marshal(struct {
+		alias
+		Delay jsonDuration `json:"delay"`
+	}{alias: alias(r), Delay: jsonDuration(r.Delay)})
+}
+
+// UnmarshalJSON 允许时长字段使用字符串或整数纳秒
+func (r *RetryConfig) UnmarshalJSON(data []byte) error {
+	type alias RetryConfig
+	aux := struct {
+		*alias
+		Delay jsonDuration `json:"delay"`
+	}{alias: (*alias)(r), Delay: jsonDuration(r.Delay)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.Delay = time.Duration(aux.Delay)
+	return nil
+}
+
+// MarshalJSON 将时长字段输出为可读字符串
+func (c ProfileConfig) MarshalJSON() ([]byte, error) {
+	type alias ProfileConfig
+	return json.Marshal(struct {
+		alias
+		Timeout jsonDuration `json:"timeout,omitempty"`
+	}{alias: alias(c), Timeout: jsonDuration(c.Timeout)})
+}
+
+// UnmarshalJSON 允许时长字段使用字符串或整数纳秒
+func (c *ProfileConfig) UnmarshalJSON(data []byte) error {
+	type alias ProfileConfig
+	aux := struct {
+		*alias
+		Timeout jsonDuration `json:"timeout,omitempty"`
+	}{alias: (*alias)(c), Timeout: jsonDuration(c.Timeout)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.Timeout = time.Duration(aux.Timeout)
+	return nil
+}
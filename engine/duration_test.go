@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigDurationParsing(t *testing.T) {
+	t.Run("Accept duration strings", func(t *testing.T) {
+		data := `{
+			"name": "durations",
+			"timeout": "1m30s",
+			"layers": [
+				{"name": "L1", "timeout": "5s", "components": [
+					{"name": "C1", "type": "X", "timeout": "250ms",
+					 "retry": {"max_retries": 2, "delay": "1s", "backoff": 1.5}}
+				]}
+			]
+		}`
+		cfg, err := NewConfigParser().ParseBytes([]byte(data))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.Timeout != 90*time.Second {
+			t.Errorf("Expected config timeout 1m30s, got %v", cfg.Timeout)
+		}
+		if cfg.Layers[0].Timeout != 5*time.Second {
+			t.Errorf("Expected layer timeout 5s, got %v", cfg.Layers[0].Timeout)
+		}
+		comp := cfg.Layers[0].Components[0]
+		if comp.Timeout != 250*time.Millisecond {
+			t.Errorf("Expected component timeout 250ms, got %v", comp.Timeout)
+		}
+		if comp.Retry == nil || comp.Retry.Delay != time.Second {
+			t.Errorf("Expected retry delay 1s, got %+v", comp.Retry)
+		}
+	})
+
+	t.Run("Accept integer nanoseconds", func(t *testing.T) {
+		data := `{"name": "legacy", "timeout": 5000000000, "layers": [
+			{"name": "L1", "timeout": 1000000000, "components": [{"name": "C1", "type": "X"}]}
+		]}`
+		cfg, err := NewConfigParser().ParseBytes([]byte(data))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.Timeout != 5*time.Second {
+			t.Errorf("Expected config timeout 5s, got %v", cfg.Timeout)
+		}
+		if cfg.Layers[0].Timeout != time.Second {
+			t.Errorf("Expected layer timeout 1s, got %v", cfg.Layers[0].Timeout)
+		}
+	})
+
+	t.Run("Reject invalid duration", func(t *testing.T) {
+		data := `{"name": "bad", "layers": [
+			{"name": "L1", "timeout": "five seconds", "components": [{"name": "C1", "type": "X"}]}
+		]}`
+		_, err := NewConfigParser().ParseBytes([]byte(data))
+		if err == nil {
+			t.Fatal("Expected error for invalid duration")
+		}
+		if _, ok := err.(*ConfigError); !ok {
+			t.Errorf("Expected ConfigError, got %T", err)
+		}
+	})
+}
+
+func TestConfigToJSONDurations(t *testing.T) {
+	cfg := &Config{
+		Name:    "readable",
+		Timeout: 2 * time.Minute,
+		Layers: []LayerConfig{
+			{
+				Name:    "L1",
+				Mode:    SerialMode,
+				Timeout: 5 * time.Second,
+				Components: []ComponentConfig{
+					{Name: "C1", Type: "X", Timeout: 30 * time.Second, Retry: &RetryConfig{MaxRetries: 1, Delay: 500 * time.Millisecond}},
+				},
+			},
+		},
+	}
+
+	out, err := cfg.ToJSON()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, want := range []string{`"timeout": "2m0s"`, `"timeout": "5s"`, `"timeout": "30s"`, `"delay": "500ms"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected JSON to contain %s, got:\n%s", want, out)
+		}
+	}
+
+	clone, err := cfg.Clone()
+	if err != nil {
+		t.Fatalf("Expected clone to succeed, got %v", err)
+	}
+	if clone.Timeout != cfg.Timeout || clone.Layers[0].Components[0].Retry.Delay != 500*time.Millisecond {
+		t.Errorf("Durations not preserved through Clone: %+v", clone)
+	}
+}
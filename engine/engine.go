@@ -18,6 +18,10 @@ type ExecutionStats struct {
 	LayerStats    map[string]*LayerStats `json:"layer_stats"`
 	Success       bool                   `json:"success"`
 	Error         error                  `json:"error,omitempty"`
+	// DataHistory 各键的写入历史，仅当传入的 DataContext 记录历史时填充（见 NewTrackedDataContext）
+	DataHistory map[string][]WriteRecord `json:"data_history,omitempty"`
+	// DataViolations 违反键约束的写入，仅当启用 WithDataSchemas 时填充
+	DataViolations []*DataContextError `json:"data_violations,omitempty"`
 }
 
 // LayerStats 层级统计信息
@@ -41,6 +45,10 @@ type Engine struct {
 	logger       Logger
 	errorHandler ErrorHandler
 	middleware   []Middleware
+	scoped       bool
+	tx           bool
+	blobs        *blobOptions
+	schemas      *schemaConfig
 	mu           sync.RWMutex
 }
 
@@ -89,6 +97,50 @@ func WithMiddleware(middleware ...Middleware) EngineOption {
 	}
 }
 
+// WithDataScopes 为每个组件创建独立的数据作用域（名称为 "layer.component"，见 ComponentScopeName）
+// 组件读取时可以看到工作流数据，写入只保留在自己的作用域中，需调用 data.Export(keys...) 发布给后续组件；
+// 执行后可通过 SnapshotScopes(data) 查看各组件分别写入的数据
+func WithDataScopes() EngineOption {
+	return func(e *Engine) {
+		e.scoped = true
+	}
+}
+
+// WithTransactionalData 组件的每次执行尝试在写时复制的事务上下文中运行：
+// 读取可以看到已有数据与本次尝试的写入，写入（包括删除与 Export）在组件成功后一次性提交，
+// 失败（包括每次失败的重试）时丢弃，后续重试与同层其他组件看不到失败尝试的部分写入
+// 并行/异步层中两个组件提交同一作用域中的同一个键时，后提交的组件失败并返回 DataContextError（commit_conflict）
+func WithTransactionalData() EngineOption {
+	return func(e *Engine) {
+		e.tx = true
+	}
+}
+
+// blobOptions 见 WithBlobStorage
+type blobOptions struct {
+	dir       string
+	threshold int
+}
+
+// WithBlobStorage 每次执行时在 dir 下创建临时的 blob 存储并关联到传入的 DataContext，
+// 通过 Set 写入的超过 threshold 字节的 []byte 值保存为 *Blob 句柄（GetBytes 透明读取内容），
+// 组件也可以用 SetBlob 流式写入；执行结束（包括失败与取消）时删除存储，句柄随之失效
+// dir 为空时使用系统临时目录
+func WithBlobStorage(dir string, threshold int) EngineOption {
+	return func(e *Engine) {
+		e.blobs = &blobOptions{dir: dir, threshold: threshold}
+	}
+}
+
+// WithDataSchemas 每次执行时为传入的 DataContext 关联键约束（见 DataSchemaRegistry）：
+// mode 为 DataSchemaReject 时违规写入被拒绝，写入的组件在本次尝试结束时以 DataContextError（schema_violation）失败；
+// 为 DataSchemaWarn 时照常写入。两种模式下违规都记录在 ExecutionStats.DataViolations 中
+func WithDataSchemas(registry *DataSchemaRegistry, mode DataSchemaMode) EngineOption {
+	return func(e *Engine) {
+		e.schemas = &schemaConfig{registry: registry, mode: mode}
+	}
+}
+
 // NewEngine 创建新的执行引擎
 func NewEngine(config *Config, registry *ComponentRegistry, options ...EngineOption) (*Engine, error) {
 	if config == nil {
@@ -124,6 +176,8 @@ func NewEngine(config *Config, registry *ComponentRegistry, options ...EngineOpt
 		if err != nil {
 			return nil, fmt.Errorf("failed to create layer %s: %w", layerConfig.Name, err)
 		}
+		layer.scoped = engine.scoped
+		layer.tx = engine.tx
 
 		// 验证层级
 		if err := layer.Validate(); err != nil {
@@ -133,6 +187,11 @@ func NewEngine(config *Config, registry *ComponentRegistry, options ...EngineOpt
 		engine.layers = append(engine.layers, layer)
 	}
 
+	// 校验组件声明（配置与 DataFlowComponent）的数据流
+	if _, err := AnalyzeDataFlow(dataFlowConfig(config, engine.layers)); err != nil {
+		return nil, fmt.Errorf("data flow validation failed: %w", err)
+	}
+
 	return engine, nil
 }
 
@@ -146,6 +205,62 @@ func (e *Engine) Execute(ctx context.Context, data DataContext) (*ExecutionStats
 
 	e.logger.Info("Starting DAG execution", "dag", e.config.Name, "layers", len(e.layers))
 
+	// DataChangeMiddleware 观察本次执行期间的所有写入
+	unwatch := watchData(ctx, data, e.middleware)
+	defer unwatch()
+
+	// 执行结束时（包括失败与取消）调用 SetWithCleanup 登记的清理函数，
+	// 清理函数可能仍需读取 blob，因此在删除大值存储之前调用
+	// 异步层的组件不被等待，可能在清理之后仍在运行，见 RunCleanups
+	var detachBlobs func()
+	defer func() {
+		if err := RunCleanups(data); err != nil {
+			e.logger.Error("Data cleanup failed", "dag", e.config.Name, "error", err)
+		}
+		if detachBlobs != nil {
+			detachBlobs()
+		}
+	}()
+
+	// 大值存储只在本次执行期间有效
+	if e.blobs != nil {
+		detach, err := e.attachBlobStore(data)
+		if err != nil {
+			e.logger.Error("Blob storage setup failed", "dag", e.config.Name, "error", err)
+			stats.EndTime = time.Now()
+			stats.Duration = stats.EndTime.Sub(stats.StartTime)
+			stats.Error = err
+			return stats, err
+		}
+		detachBlobs = detach
+	}
+
+	// 键约束与违规记录只在本次执行期间有效
+	if e.schemas != nil {
+		holder, ok := data.(interface {
+			attachSchemas(cfg *schemaConfig) func()
+		})
+		if !ok {
+			err := &DataContextError{Type: "schema_unsupported", Message: fmt.Sprintf("%T does not support data schemas", data)}
+			e.logger.Error("Data schema setup failed", "dag", e.config.Name, "error", err)
+			stats.EndTime = time.Now()
+			stats.Duration = stats.EndTime.Sub(stats.StartTime)
+			stats.Error = err
+			return stats, err
+		}
+		// 每次执行使用新的记录，违规不会跨执行累积
+		defer holder.attachSchemas(&schemaConfig{registry: e.schemas.registry, mode: e.schemas.mode})()
+	}
+
+	// 校验声明的输入并写入默认值，任何层执行之前报告所有缺失的输入
+	if err := e.config.ApplyInputs(data); err != nil {
+		e.logger.Error("Workflow input validation failed", "dag", e.config.Name, "error", err)
+		stats.EndTime = time.Now()
+		stats.Duration = stats.EndTime.Sub(stats.StartTime)
+		stats.Error = err
+		return stats, err
+	}
+
 	// 执行前置中间件
 	for _, middleware := range e.middleware {
 		if err := middleware.BeforeExecution(ctx, e.config); err != nil {
@@ -236,6 +351,11 @@ func (e *Engine) Execute(ctx context.Context, data DataContext) (*ExecutionStats
 	stats.Duration = stats.EndTime.Sub(stats.StartTime)
 	stats.Success = executionError == nil
 	stats.Error = executionError
+	stats.DataHistory = DataHistory(data)
+	stats.DataViolations = DataViolations(data)
+	for _, violation := range stats.DataViolations {
+		e.logger.Warn("Data schema violation", "key", violation.Key, "component", violation.Component, "layer", violation.Layer, "error", violation.Message)
+	}
 
 	// 执行后置中间件
 	for _, middleware := range e.middleware {
@@ -261,6 +381,27 @@ func (e *Engine) Execute(ctx context.Context, data DataContext) (*ExecutionStats
 	return stats, stats.Error
 }
 
+// attachBlobStore 创建本次执行的 blob 存储并关联到 data，返回恢复并删除存储的函数
+func (e *Engine) attachBlobStore(data DataContext) (func(), error) {
+	holder, ok := data.(interface {
+		attachBlobs(cfg *blobConfig) func()
+	})
+	if !ok {
+		return nil, &DataContextError{Type: "blob_store_unsupported", Message: fmt.Sprintf("%T does not support blob storage", data)}
+	}
+	store, err := NewBlobStore(e.blobs.dir)
+	if err != nil {
+		return nil, err
+	}
+	restore := holder.attachBlobs(&blobConfig{store: store, threshold: e.blobs.threshold})
+	return func() {
+		restore()
+		if err := store.Close(); err != nil {
+			e.logger.Warn("Blob storage cleanup failed", "dir", store.Dir(), "error", err)
+		}
+	}, nil
+}
+
 // GetConfig 获取配置
 func (e *Engine) GetConfig() *Config {
 	e.mu.RLock()
@@ -16,9 +16,9 @@ func TestNewEngine(t *testing.T) {
 					Name: "layer1",
 					Mode: SerialMode,
 					Components: []ComponentConfig{
-						{Name: "comp1", Type: "test-type", Enabled: true},
+						{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
 					},
-					Enabled: true,
+					Enabled: BoolPtr(true),
 				},
 			},
 		}
@@ -72,7 +72,7 @@ func TestNewEngine(t *testing.T) {
 				{
 					Name:    "", // Empty name should cause validation error
 					Mode:    SerialMode,
-					Enabled: true,
+					Enabled: BoolPtr(true),
 				},
 			},
 		}
@@ -96,9 +96,9 @@ func TestEngineWithLogger(t *testing.T) {
 				Name: "layer1",
 				Mode: SerialMode,
 				Components: []ComponentConfig{
-					{Name: "comp1", Type: "test-type", Enabled: true},
+					{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
 				},
-				Enabled: true,
+				Enabled: BoolPtr(true),
 			},
 		},
 	}
@@ -132,9 +132,9 @@ func TestEngineWithErrorHandler(t *testing.T) {
 				Name: "layer1",
 				Mode: SerialMode,
 				Components: []ComponentConfig{
-					{Name: "comp1", Type: "test-type", Enabled: true},
+					{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
 				},
-				Enabled: true,
+				Enabled: BoolPtr(true),
 			},
 		},
 	}
@@ -168,9 +168,9 @@ func TestEngineWithMiddleware(t *testing.T) {
 				Name: "layer1",
 				Mode: SerialMode,
 				Components: []ComponentConfig{
-					{Name: "comp1", Type: "test-type", Enabled: true},
+					{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
 				},
-				Enabled: true,
+				Enabled: BoolPtr(true),
 			},
 		},
 	}
@@ -204,9 +204,9 @@ func TestEngineGetters(t *testing.T) {
 				Name: "layer1",
 				Mode: SerialMode,
 				Components: []ComponentConfig{
-					{Name: "comp1", Type: "test-type", Enabled: true},
+					{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
 				},
-				Enabled: true,
+				Enabled: BoolPtr(true),
 			},
 		},
 	}
@@ -269,9 +269,9 @@ func TestEngineValidate(t *testing.T) {
 					Name: "layer1",
 					Mode: SerialMode,
 					Components: []ComponentConfig{
-						{Name: "comp1", Type: "test-type", Enabled: true},
+						{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
 					},
-					Enabled: true,
+					Enabled: BoolPtr(true),
 				},
 			},
 		}
@@ -300,9 +300,9 @@ func TestEngineValidate(t *testing.T) {
 					Name: "layer1",
 					Mode: SerialMode,
 					Components: []ComponentConfig{
-						{Name: "comp1", Type: "test-type", Enabled: true},
+						{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
 					},
-					Enabled: true,
+					Enabled: BoolPtr(true),
 				},
 			},
 		}
@@ -333,9 +333,9 @@ func TestEngineExecute(t *testing.T) {
 					Name: "layer1",
 					Mode: SerialMode,
 					Components: []ComponentConfig{
-						{Name: "comp1", Type: "test-type", Enabled: true},
+						{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
 					},
-					Enabled: true,
+					Enabled: BoolPtr(true),
 				},
 			},
 		}
@@ -377,9 +377,9 @@ func TestEngineExecute(t *testing.T) {
 					Name: "layer1",
 					Mode: SerialMode,
 					Components: []ComponentConfig{
-						{Name: "comp1", Type: "test-type", Enabled: true},
+						{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
 					},
-					Enabled: true,
+					Enabled: BoolPtr(true),
 				},
 			},
 		}
@@ -427,9 +427,9 @@ func TestEngineExecute(t *testing.T) {
 					Name: "layer1",
 					Mode: SerialMode,
 					Components: []ComponentConfig{
-						{Name: "comp1", Type: "test-type", Enabled: true},
+						{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
 					},
-					Enabled: true,
+					Enabled: BoolPtr(true),
 				},
 			},
 		}
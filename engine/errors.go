@@ -2,6 +2,7 @@ package engine
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -112,6 +113,27 @@ func (e *CriticalComponentError) Unwrap() error {
 	return e.Cause
 }
 
+// DataContextError 数据上下文操作错误
+type DataContextError struct {
+	Type      string `json:"type"`
+	Key       string `json:"key"`
+	Message   string `json:"message"`
+	Component string `json:"component,omitempty"`
+	Layer     string `json:"layer,omitempty"`
+	Cause     error  `json:"cause,omitempty"`
+}
+
+func (e *DataContextError) Error() string {
+	if e.Component != "" {
+		return fmt.Sprintf("data context error for key %s (component %s): %s", e.Key, e.Component, e.Message)
+	}
+	return fmt.Sprintf("data context error for key %s: %s", e.Key, e.Message)
+}
+
+func (e *DataContextError) Unwrap() error {
+	return e.Cause
+}
+
 // ValidationError 验证错误
 type ValidationError struct {
 	Field   string      `json:"field"`
@@ -122,3 +144,17 @@ type ValidationError struct {
 func (e *ValidationError) Error() string {
 	return fmt.Sprintf("validation error for field %s: %s", e.Field, e.Message)
 }
+
+// ValidationErrors 多个验证错误的集合
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, fmt.Sprintf("%s: %s", err.Field, err.Message))
+	}
+	return fmt.Sprintf("%d validation errors: %s", len(e), strings.Join(messages, "; "))
+}
@@ -243,4 +243,24 @@ func TestValidationError(t *testing.T) {
 			t.Errorf("Expected %q, got %q", expected, err.Error())
 		}
 	})
-}
\ No newline at end of file
+}
+func TestValidationErrors(t *testing.T) {
+	t.Run("Single error", func(t *testing.T) {
+		errs := ValidationErrors{{Field: "a", Message: "is required"}}
+		expected := "validation error for field a: is required"
+		if errs.Error() != expected {
+			t.Errorf("Expected %q, got %q", expected, errs.Error())
+		}
+	})
+
+	t.Run("Multiple errors", func(t *testing.T) {
+		errs := ValidationErrors{
+			{Field: "a", Message: "is required"},
+			{Field: "b", Message: "must be a string"},
+		}
+		expected := "2 validation errors: a: is required; b: must be a string"
+		if errs.Error() != expected {
+			t.Errorf("Expected %q, got %q", expected, errs.Error())
+		}
+	})
+}
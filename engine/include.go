@@ -0,0 +1,222 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// includeKey layers/components 数组中引用片段文件的键
+	includeKey = "$include"
+	// includeParamsKey 片段参数的键，参数以 ${name} 的形式在片段中引用
+	includeParamsKey = "params"
+)
+
+// expandIncludes 展开配置中 layers 与 components 数组里的 $include 片段
+// 片段文件可以是单个对象或对象数组，路径相对于包含它的文件解析；
+// params 中的参数会在片段内以 ${name} 的形式替换，未提供的参数回退到解析器的变量来源
+func (p *ConfigParser) expandIncludes(state *parseState, raw interface{}, dir string) error {
+	root, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if err := p.expandLayerList(state, root, dir, "layers"); err != nil {
+		return err
+	}
+
+	if profiles, ok := root["profiles"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if profile, ok := profiles[name].(map[string]interface{}); ok {
+				if err := p.expandLayerList(state, profile, dir, "profiles."+name+".layers"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// expandLayerList 展开层数组及各层组件数组中的 $include
+func (p *ConfigParser) expandLayerList(state *parseState, parent map[string]interface{}, dir, path string) error {
+	layers, err := p.expandList(state, parent["layers"], layerConfigType, dir, path)
+	if err != nil || layers == nil {
+		return err
+	}
+
+	for i, item := range layers {
+		layer, ok := item.value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		components, err := p.expandList(state, layer["components"], componentConfigType, item.dir, fmt.Sprintf("%s[%d].components", path, i))
+		if err != nil {
+			return err
+		}
+		if components != nil {
+			layer["components"] = components.values()
+		}
+	}
+	parent["layers"] = layers.values()
+	return nil
+}
+
+// includedItem 展开后的数组元素及其来源文件所在目录（用于解析嵌套 $include）
+type includedItem struct {
+	value interface{}
+	dir   string
+}
+
+// includedItems 展开后的数组
+type includedItems []includedItem
+
+// values 返回展开后的元素值
+func (items includedItems) values() []interface{} {
+	values := make([]interface{}, len(items))
+	for i, item := range items {
+		values[i] = item.value
+	}
+	return values
+}
+
+// expandList 展开数组中的 $include 元素，非数组值返回 nil；target 为数组元素的配置类型
+func (p *ConfigParser) expandList(state *parseState, value interface{}, target reflect.Type, dir, path string) (includedItems, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	expanded := make(includedItems, 0, len(list))
+	for i, item := range list {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		obj, ok := item.(map[string]interface{})
+		if !ok || obj[includeKey] == nil {
+			expanded = append(expanded, includedItem{value: item, dir: dir})
+			continue
+		}
+
+		fragment, err := p.loadFragment(state, obj, target, dir, itemPath)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, fragment...)
+	}
+	return expanded, nil
+}
+
+// loadFragment 读取 $include 引用的片段并替换参数，片段内的 $include 会递归展开
+func (p *ConfigParser) loadFragment(state *parseState, obj map[string]interface{}, target reflect.Type, dir, path string) (includedItems, error) {
+	ref, ok := obj[includeKey].(string)
+	if !ok || ref == "" {
+		return nil, &ConfigError{
+			Type:    "invalid_include",
+			Field:   joinFieldPath(path, includeKey),
+			Message: "$include must be a non-empty file path",
+		}
+	}
+	for key := range obj {
+		if key != includeKey && key != includeParamsKey {
+			return nil, &ConfigError{
+				Type:    "invalid_include",
+				Field:   joinFieldPath(path, key),
+				Message: fmt.Sprintf("unexpected field %s next to $include (only %s is allowed)", key, includeParamsKey),
+			}
+		}
+	}
+
+	params := make(map[string]string)
+	// 非字符串参数在整个值为单个 ${name} 时按原 JSON 类型替换，其余位置使用字符串形式
+	typed := make(map[string]interface{})
+	if rawParams, exists := obj[includeParamsKey]; exists {
+		paramMap, ok := rawParams.(map[string]interface{})
+		if !ok {
+			return nil, &ConfigError{
+				Type:    "invalid_include",
+				Field:   joinFieldPath(path, includeParamsKey),
+				Message: "params must be an object",
+			}
+		}
+		for k, v := range paramMap {
+			params[k] = includeParamString(v)
+			if _, ok := v.(string); !ok {
+				typed[k] = v
+			}
+		}
+	}
+
+	name := state.resolve(dir, ref)
+	key := state.key(name)
+	if state.visiting[key] {
+		return nil, &ConfigError{
+			Type:    "include_cycle_detected",
+			Field:   path,
+			Message: fmt.Sprintf("circular $include detected: %s", ref),
+		}
+	}
+	state.visiting[key] = true
+	defer delete(state.visiting, key)
+
+	data, err := state.readFile(name)
+	if err != nil {
+		return nil, &ConfigError{
+			Type:    "file_open_failed",
+			Field:   joinFieldPath(path, includeKey),
+			Message: fmt.Sprintf("failed to open include file: %v", err),
+			Cause:   err,
+		}
+	}
+	raw, err := decodeRawJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	source := ChainSources(MapSource(params), p.variables)
+	targetType := target
+	if _, ok := raw.([]interface{}); ok {
+		targetType = reflect.SliceOf(target)
+	}
+	if raw, err = coerceReferences(source, typed, raw, targetType, path); err != nil {
+		return nil, err
+	}
+	raw, err = interpolateTyped(source, typed, raw, path)
+	if err != nil {
+		return nil, err
+	}
+
+	fragmentDir := state.dir(name)
+	if list, ok := raw.([]interface{}); ok {
+		return p.expandList(state, list, target, fragmentDir, path)
+	}
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, &ConfigError{
+			Type:    "invalid_include",
+			Field:   joinFieldPath(path, includeKey),
+			Message: fmt.Sprintf("include file %s must contain an object or an array of objects", ref),
+		}
+	}
+	return p.expandList(state, []interface{}{raw}, target, fragmentDir, path)
+}
+
+// includeParamString 将参数值转换为替换用的字符串
+func includeParamString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestConfigIncludeFragments(t *testing.T) {
+	fsys := fstest.MapFS{
+		"common/notify_layer.json": {Data: []byte(`{
+			"name": "notify_${channel}",
+			"components": [
+				{"$include": "components/notifier.json", "params": {"channel": "${channel}", "retries": "${retries:1}"}}
+			]
+		}`)},
+		"common/components/notifier.json": {Data: []byte(`{
+			"name": "notify_${channel}", "type": "notifier",
+			"config": {"channel": "${channel}", "retries": "${retries}"}
+		}`)},
+		"common/pair.json": {Data: []byte(`[
+			{"name": "A", "type": "X"},
+			{"name": "B", "type": "X"}
+		]`)},
+		"workflows/main.json": {Data: []byte(`{
+			"name": "main",
+			"layers": [
+				{"name": "L1", "components": [{"$include": "../common/pair.json"}]},
+				{"$include": "../common/notify_layer.json", "params": {"channel": "slack"}},
+				{"$include": "../common/notify_layer.json", "params": {"channel": "email", "retries": 3}}
+			]
+		}`)},
+	}
+
+	cfg, err := NewConfigParser().ParseFS(fsys, "workflows/main.json")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(cfg.Layers) != 3 {
+		t.Fatalf("Expected 3 layers, got %d", len(cfg.Layers))
+	}
+	if got := cfg.Layers[0].Components; len(got) != 2 || got[0].Name != "A" || got[1].Name != "B" {
+		t.Errorf("Expected array fragment to be spliced, got %+v", got)
+	}
+
+	slack := findComponent(findLayer(cfg, "notify_slack"), "notify_slack")
+	if slack == nil || slack.Config["channel"] != "slack" || slack.Config["retries"] != "1" {
+		t.Errorf("Unexpected slack notifier: %+v", slack)
+	}
+	email := findComponent(findLayer(cfg, "notify_email"), "notify_email")
+	// 整个值为单个 ${retries} 时保留数值类型
+	if email == nil || email.Config["channel"] != "email" || email.Config["retries"] != float64(3) {
+		t.Errorf("Unexpected email notifier: %+v", email)
+	}
+}
+
+func TestConfigIncludeTypedParams(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "component.json", `{
+		"name": "C_${retries}", "type": "X", "critical": "${critical}",
+		"config": {"retries": "${retries}", "label": "try ${retries}", "tags": "${tags}", "missing": "${missing:none}"}
+	}`)
+	path := writeFile(t, dir, "main.json", `{"name": "main", "layers": [{"name": "L1", "components": [
+		{"$include": "component.json", "params": {"retries": 2, "critical": true, "tags": ["a", "b"]}}
+	]}]}`)
+
+	cfg, err := NewConfigParser(WithStrictMode()).ParseFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	component := cfg.Layers[0].Components[0]
+	if component.Name != "C_2" || !component.IsCritical() {
+		t.Errorf("Expected typed params in a fragment, got %+v", component)
+	}
+	if component.Config["retries"] != float64(2) || component.Config["label"] != "try 2" || component.Config["missing"] != "none" {
+		t.Errorf("Unexpected config: %#v", component.Config)
+	}
+	if tags, ok := component.Config["tags"].([]interface{}); !ok || len(tags) != 2 {
+		t.Errorf("Expected array param to stay an array, got %#v", component.Config["tags"])
+	}
+}
+
+func TestConfigIncludeFallsBackToVariables(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "layer.json", `{"name": "${LAYER}", "parallel": "${PARALLEL}", "components": [{"name": "C1", "type": "${TYPE:X}"}]}`)
+	path := writeFile(t, dir, "main.json", `{"name": "main", "layers": [{"$include": "layer.json", "params": {"PARALLEL": "2"}}]}`)
+
+	cfg, err := NewConfigParser(WithVariableSource(MapSource(map[string]string{"LAYER": "from_env"}))).ParseFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Layers[0].Name != "from_env" || cfg.Layers[0].Components[0].Type != "X" {
+		t.Errorf("Unexpected layer: %+v", cfg.Layers[0])
+	}
+	// 字符串参数整体引用在数值字段中时按字段类型解析
+	if cfg.Layers[0].Parallel != 2 {
+		t.Errorf("Expected parallel 2, got %d", cfg.Layers[0].Parallel)
+	}
+}
+
+func TestConfigIncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "loop.json", `{"name": "L", "components": [{"$include": "loop_component.json"}]}`)
+	writeFile(t, dir, "loop_component.json", `[{"$include": "loop_component.json"}]`)
+	writeFile(t, dir, "scalar.json", `"not an object"`)
+
+	cases := []struct {
+		name     string
+		layers   string
+		wantType string
+		field    string
+	}{
+		{"Extra fields", `[{"$include": "loop.json", "name": "x"}]`, "invalid_include", "layers[0].name"},
+		{"Params not an object", `[{"$include": "loop.json", "params": "x"}]`, "invalid_include", "layers[0].params"},
+		{"Empty path", `[{"$include": ""}]`, "invalid_include", "layers[0].$include"},
+		{"Missing file", `[{"$include": "missing.json"}]`, "file_open_failed", "layers[0].$include"},
+		{"Scalar fragment", `[{"$include": "scalar.json"}]`, "invalid_include", "layers[0].$include"},
+		{"Cycle", `[{"$include": "loop.json"}]`, "include_cycle_detected", "layers[0].components[0][0]"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeFile(t, dir, "main.json", `{"name": "main", "layers": `+tc.layers+`}`)
+			_, err := NewConfigParser().ParseFile(path)
+			configErr, ok := err.(*ConfigError)
+			if !ok || configErr.Type != tc.wantType {
+				t.Fatalf("Expected %s, got %v", tc.wantType, err)
+			}
+			if configErr.Field != tc.field {
+				t.Errorf("Expected field %s, got %s", tc.field, configErr.Field)
+			}
+		})
+	}
+
+	// 同一片段在不同位置重复引用不属于循环
+	writeFile(t, dir, "c.json", `{"name": "C", "type": "X"}`)
+	path := writeFile(t, dir, "main.json", `{"name": "main", "layers": [
+		{"name": "L1", "components": [{"$include": "c.json"}]},
+		{"name": "L2", "components": [{"$include": "`+filepath.ToSlash(filepath.Join(dir, "c.json"))+`"}]}
+	]}`)
+	if _, err := NewConfigParser().ParseFile(path); err != nil {
+		t.Fatalf("Expected repeated include to succeed, got %v", err)
+	}
+}
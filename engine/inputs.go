@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// InputType 工作流输入的类型
+type InputType string
+
+const (
+	InputTypeAny      InputType = "any"      // 不检查类型
+	InputTypeString   InputType = "string"   // 字符串
+	InputTypeNumber   InputType = "number"   // 任意数值
+	InputTypeInteger  InputType = "integer"  // 整数（整数值的浮点数也可接受）
+	InputTypeBoolean  InputType = "boolean"  // 布尔值
+	InputTypeObject   InputType = "object"   // 字符串键的 map
+	InputTypeArray    InputType = "array"    // 切片或数组
+	InputTypeDuration InputType = "duration" // time.Duration 或时长字符串
+)
+
+// InputConfig 工作流声明的输入参数
+// 引擎在 Execute 开始时按声明检查 DataContext：缺失的输入写入默认值，
+// 必需输入缺失或类型不匹配时在任何层执行之前返回 ValidationErrors
+type InputConfig struct {
+	Name        string      `json:"name"`
+	Type        InputType   `json:"type,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Description string      `json:"description,omitempty"`
+}
+
+// validateInputs 验证输入声明：名称非空且唯一、类型合法、默认值与类型一致
+func (p *ConfigParser) validateInputs(inputs []InputConfig) error {
+	names := make(map[string]bool)
+	for i, input := range inputs {
+		if input.Name == "" {
+			return &ValidationError{
+				Field:   fmt.Sprintf("inputs[%d].name", i),
+				Value:   input.Name,
+				Message: "input name cannot be empty",
+			}
+		}
+		if names[input.Name] {
+			return &ValidationError{
+				Field:   fmt.Sprintf("inputs[%d].name", i),
+				Value:   input.Name,
+				Message: fmt.Sprintf("duplicate input name: %s", input.Name),
+			}
+		}
+		names[input.Name] = true
+
+		if !input.Type.valid() {
+			return &ValidationError{
+				Field:   fmt.Sprintf("inputs[%d].type", i),
+				Value:   input.Type,
+				Message: fmt.Sprintf("invalid input type: %s", input.Type),
+			}
+		}
+		if input.Default != nil {
+			if _, err := input.Type.convert(input.Default); err != nil {
+				return &ValidationError{
+					Field:   fmt.Sprintf("inputs[%d].default", i),
+					Value:   input.Default,
+					Message: err.Error(),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// mergeInputs 按名称合并输入声明，子配置的同名输入整体覆盖父配置
+func mergeInputs(base, child []InputConfig) []InputConfig {
+	idx := make(map[string]int, len(base))
+	for i, input := range base {
+		idx[input.Name] = i
+	}
+	for _, input := range child {
+		if i, ok := idx[input.Name]; ok {
+			base[i] = input
+			continue
+		}
+		idx[input.Name] = len(base)
+		base = append(base, input)
+	}
+	return base
+}
+
+// ApplyInputs 按 Config.Inputs 检查并补全 DataContext
+// 未提供的输入写入默认值；提供的值与默认值按相同规则规范化后写回
+// （integer 转换为 int，duration 字符串转换为 time.Duration），组件读取到的类型与值的来源无关
+// 所有缺失的必需输入与类型不匹配的输入以 ValidationErrors 一次性返回，字段形如 inputs.user_id
+func (c *Config) ApplyInputs(data DataContext) error {
+	var errs ValidationErrors
+	defaults := make(map[string]interface{})
+	for _, input := range c.Inputs {
+		field := joinFieldPath("inputs", input.Name)
+		value, ok := data.Get(input.Name)
+		if ok {
+			converted, err := input.Type.convert(value)
+			if err != nil {
+				errs = append(errs, &ValidationError{Field: field, Value: value, Message: err.Error()})
+				continue
+			}
+			// 只写回类型发生变化的值，避免无意义的写入与变更通知
+			if !reflect.DeepEqual(converted, value) {
+				defaults[input.Name] = converted
+			}
+			continue
+		}
+
+		if input.Default != nil {
+			converted, err := input.Type.convert(input.Default)
+			if err != nil {
+				errs = append(errs, &ValidationError{Field: field, Value: input.Default, Message: err.Error()})
+				continue
+			}
+			defaults[input.Name] = converted
+			continue
+		}
+		if input.Required {
+			errs = append(errs, &ValidationError{Field: field, Message: "required input is missing"})
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+		return errs
+	}
+	// 全部校验通过后才写入默认值与规范化后的值，避免失败时留下部分修改
+	for name, value := range defaults {
+		data.Set(name, value)
+	}
+	return nil
+}
+
+// valid 判断类型是否合法，空类型等同于 any
+func (t InputType) valid() bool {
+	switch t {
+	case "", InputTypeAny, InputTypeString, InputTypeNumber, InputTypeInteger,
+		InputTypeBoolean, InputTypeObject, InputTypeArray, InputTypeDuration:
+		return true
+	}
+	return false
+}
+
+// convert 检查值是否符合类型，返回规范化后的值
+func (t InputType) convert(value interface{}) (interface{}, error) {
+	mismatch := func() error {
+		return fmt.Errorf("expected %s, got %s", t, jsonTypeName(value))
+	}
+
+	switch t {
+	case "", InputTypeAny:
+		return value, nil
+	case InputTypeInteger:
+		// 任何整数类型与整数值的浮点数都规范化为 int
+		if value != nil {
+			rv := reflect.ValueOf(value)
+			switch rv.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return int(rv.Int()), nil
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				return int(rv.Uint()), nil
+			}
+		}
+		n, ok := toFloat64(value)
+		if !ok || n != math.Trunc(n) {
+			return nil, mismatch()
+		}
+		return int(n), nil
+	case InputTypeDuration:
+		// 与配置中的 timeout 等字段一致：整数与整数值的浮点数按纳秒解释
+		return toDuration(value)
+	}
+
+	if !schemaTypeMatches(SchemaType{string(t)}, value) {
+		return nil, mismatch()
+	}
+	return value, nil
+}
@@ -0,0 +1,200 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestConfigInputsParsing(t *testing.T) {
+	t.Run("Valid inputs", func(t *testing.T) {
+		cfg, err := NewConfigParser().ParseBytes([]byte(`{
+			"name": "inputs",
+			"inputs": [
+				{"name": "user_id", "type": "string", "required": true},
+				{"name": "limit", "type": "integer", "default": 10},
+				{"name": "window", "type": "duration", "default": "5m"}
+			],
+			"layers": [{"name": "L1", "components": [{"name": "C1", "type": "X"}]}]
+		}`))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(cfg.Inputs) != 3 || !cfg.Inputs[0].Required || cfg.Inputs[2].Type != InputTypeDuration {
+			t.Errorf("Unexpected inputs: %+v", cfg.Inputs)
+		}
+	})
+
+	cases := []struct {
+		name   string
+		inputs string
+		field  string
+	}{
+		{"Empty name", `[{"type": "string"}]`, "inputs[0].name"},
+		{"Duplicate name", `[{"name": "a"}, {"name": "a"}]`, "inputs[1].name"},
+		{"Unknown type", `[{"name": "a", "type": "date"}]`, "inputs[0].type"},
+		{"Default type mismatch", `[{"name": "a", "type": "integer", "default": 1.5}]`, "inputs[0].default"},
+		{"Invalid duration default", `[{"name": "a", "type": "duration", "default": "soon"}]`, "inputs[0].default"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewConfigParser().ParseBytes([]byte(`{"name": "inputs", "inputs": ` + tc.inputs + `,
+				"layers": [{"name": "L1", "components": [{"name": "C1", "type": "X"}]}]}`))
+			validationErr, ok := err.(*ValidationError)
+			if !ok || validationErr.Field != tc.field {
+				t.Fatalf("Expected validation error for %s, got %v", tc.field, err)
+			}
+		})
+	}
+}
+
+func TestConfigInputsExtends(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "base.json", `{"name": "base",
+		"inputs": [{"name": "a", "type": "string"}, {"name": "b", "type": "integer", "default": 1}],
+		"layers": [{"name": "L1", "components": [{"name": "C1", "type": "X"}]}]}`)
+	path := writeFile(t, dir, "child.json", `{"extends": "base.json", "name": "child",
+		"inputs": [{"name": "b", "type": "integer", "default": 2}, {"name": "c", "required": true}]}`)
+
+	cfg, err := NewConfigParser().ParseFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(cfg.Inputs) != 3 || cfg.Inputs[0].Name != "a" || cfg.Inputs[1].Default != float64(2) || cfg.Inputs[2].Name != "c" {
+		t.Errorf("Unexpected merged inputs: %+v", cfg.Inputs)
+	}
+}
+
+func TestConfigApplyInputs(t *testing.T) {
+	cfg := &Config{Inputs: []InputConfig{
+		{Name: "user_id", Type: InputTypeString, Required: true},
+		{Name: "limit", Type: InputTypeInteger, Default: float64(10)},
+		{Name: "window", Type: InputTypeDuration, Default: "5m"},
+		{Name: "tags", Type: InputTypeArray},
+		{Name: "dry_run", Type: InputTypeBoolean, Required: true},
+	}}
+
+	t.Run("Defaults are applied", func(t *testing.T) {
+		data := NewDataContextWith(map[string]interface{}{"user_id": "u1", "dry_run": true})
+		if err := cfg.ApplyInputs(data); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if v, _ := data.Get("limit"); v != 10 {
+			t.Errorf("Expected integer default 10, got %#v", v)
+		}
+		if v, _ := data.Get("window"); v != 5*time.Minute {
+			t.Errorf("Expected duration default 5m, got %#v", v)
+		}
+		if data.Has("tags") {
+			t.Error("Optional input without default should stay unset")
+		}
+	})
+
+	t.Run("Provided values are normalized", func(t *testing.T) {
+		tags := []interface{}{"a"}
+		data := NewDataContextWith(map[string]interface{}{
+			"user_id": "u1", "dry_run": true, "limit": float64(20), "window": "30s", "tags": tags,
+		})
+		if err := cfg.ApplyInputs(data); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if v, _ := data.Get("limit"); v != 20 {
+			t.Errorf("Expected provided integer to be normalized to int, got %#v", v)
+		}
+		if v, _ := data.Get("window"); v != 30*time.Second {
+			t.Errorf("Expected provided duration string to be parsed, got %#v", v)
+		}
+		if v, _ := data.Get("tags"); len(v.([]interface{})) != 1 {
+			t.Errorf("Expected other values to be kept, got %#v", v)
+		}
+
+		data = NewDataContextWith(map[string]interface{}{"user_id": "u1", "dry_run": true, "limit": int64(7)})
+		cfg.ApplyInputs(data)
+		if v, _ := data.Get("limit"); v != 7 {
+			t.Errorf("Expected int64 to be normalized to int, got %#v", v)
+		}
+	})
+
+	t.Run("Numeric durations are nanoseconds", func(t *testing.T) {
+		for _, window := range []interface{}{int(2e9), int64(2e9), float64(2e9)} {
+			data := NewDataContextWith(map[string]interface{}{"user_id": "u1", "dry_run": true, "window": window})
+			if err := cfg.ApplyInputs(data); err != nil {
+				t.Fatalf("Expected %T duration to be accepted, got %v", window, err)
+			}
+			if v, _ := data.Get("window"); v != 2*time.Second {
+				t.Errorf("Expected %T nanoseconds to become 2s, got %#v", window, v)
+			}
+		}
+
+		data := NewDataContextWith(map[string]interface{}{"user_id": "u1", "dry_run": true, "window": 1.5})
+		if err := cfg.ApplyInputs(data); err == nil {
+			t.Error("Expected fractional nanoseconds to be rejected")
+		}
+	})
+
+	t.Run("All problems are reported at once", func(t *testing.T) {
+		data := NewDataContextWith(map[string]interface{}{"limit": "ten"})
+		err := cfg.ApplyInputs(data)
+		errs, ok := err.(ValidationErrors)
+		if !ok || len(errs) != 3 {
+			t.Fatalf("Expected 3 validation errors, got %v", err)
+		}
+		want := []string{"inputs.dry_run", "inputs.limit", "inputs.user_id"}
+		for i, field := range want {
+			if errs[i].Field != field {
+				t.Errorf("Expected error %d for %s, got %s", i, field, errs[i].Field)
+			}
+		}
+		if data.Has("window") {
+			t.Error("Defaults should not be applied when validation fails")
+		}
+	})
+}
+
+func TestEngineExecuteValidatesInputs(t *testing.T) {
+	executed := false
+	config := &Config{
+		Name:   "inputs",
+		Inputs: []InputConfig{{Name: "a", Required: true}, {Name: "b", Required: true}, {Name: "c", Default: "x"}},
+		Layers: []LayerConfig{{
+			Name:       "layer1",
+			Mode:       SerialMode,
+			Components: []ComponentConfig{{Name: "comp1", Type: "test-type"}},
+		}},
+	}
+
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "test-type",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			return &MockComponent{name: config.Name, executeFunc: func(ctx context.Context, data DataContext) error {
+				executed = true
+				if v, _ := data.GetString("c"); v != "x" {
+					t.Errorf("Expected default input c=x, got %q", v)
+				}
+				return nil
+			}}, nil
+		},
+	})
+
+	engine, err := NewEngine(config, registry, WithLogger(&MockLogger{}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stats, err := engine.Execute(context.Background(), NewDataContext())
+	errs, ok := err.(ValidationErrors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("Expected 2 missing inputs, got %v", err)
+	}
+	if executed || stats.Success || stats.Error == nil {
+		t.Error("Expected no layer to run when inputs are missing")
+	}
+
+	if _, err := engine.Execute(context.Background(), NewDataContextWith(map[string]interface{}{"a": 1, "b": 2})); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !executed {
+		t.Error("Expected component to run")
+	}
+}
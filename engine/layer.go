@@ -26,16 +26,23 @@ type LayerConfig struct {
     Components   []ComponentConfig `json:"components"`
     Timeout      time.Duration     `json:"timeout"`
     Dependencies []string          `json:"dependencies"`
-    Enabled      bool              `json:"enabled"`
+    Enabled      *bool             `json:"enabled,omitempty"` // nil 表示未设置，默认 true
     Parallel     int               `json:"parallel,omitempty"` // 并行度限制
     Remove       bool              `json:"remove,omitempty"`
 }
 
+// IsEnabled 返回层级是否启用，未设置时默认启用
+func (c LayerConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
 // Layer 表示 DAG 中的一个层级
 type Layer struct {
 	config     LayerConfig
 	components []Component
 	registry   *ComponentRegistry
+	scoped     bool // 为每个组件创建独立的数据作用域，见 WithDataScopes
+	tx         bool // 每次执行尝试使用写时复制的事务上下文，见 WithTransactionalData
 }
 
 // NewLayer 创建新的层级
@@ -48,7 +55,7 @@ func NewLayer(config LayerConfig, registry *ComponentRegistry) (*Layer, error) {
 
 	// 创建组件实例
 	for _, componentConfig := range config.Components {
-		if !componentConfig.Enabled {
+		if !componentConfig.IsEnabled() {
 			continue
 		}
 
@@ -67,6 +74,11 @@ func NewLayer(config LayerConfig, registry *ComponentRegistry) (*Layer, error) {
 	return layer, nil
 }
 
+// ComponentScopeName 返回组件在启用数据作用域时使用的作用域名称
+func ComponentScopeName(layer, component string) string {
+	return layer + "." + component
+}
+
 // Name 返回层级名称
 func (l *Layer) Name() string {
 	return l.config.Name
@@ -84,12 +96,12 @@ func (l *Layer) Components() []Component {
 
 // Execute 执行层级中的所有组件
 func (l *Layer) Execute(ctx context.Context, data DataContext) error {
-	if !l.config.Enabled {
+	if !l.config.IsEnabled() {
 		return nil
 	}
 
-	// 设置超时
-	if l.config.Timeout > 0 {
+	// 设置超时；异步层在返回后组件仍在运行，超时由每个组件各自计时（见 executeAsync）
+	if l.config.Timeout > 0 && l.config.Mode != AsyncMode {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, l.config.Timeout)
 		defer cancel()
@@ -114,7 +126,7 @@ func (l *Layer) Execute(ctx context.Context, data DataContext) error {
 // executeSerial 串行执行组件
 func (l *Layer) executeSerial(ctx context.Context, data DataContext) error {
 	for _, component := range l.components {
-		if err := l.executeComponent(ctx, component, data); err != nil {
+		if err := l.executeComponent(ctx, component, data, nil); err != nil {
 			return err
 		}
 	}
@@ -133,6 +145,7 @@ func (l *Layer) executeParallel(ctx context.Context, data DataContext) error {
 	}
 
 	semaphore := make(chan struct{}, parallel)
+	group := newCommitGroup()
 
 	for _, component := range l.components {
 		wg.Add(1)
@@ -143,7 +156,7 @@ func (l *Layer) executeParallel(ctx context.Context, data DataContext) error {
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			if err := l.executeComponent(ctx, comp, data); err != nil {
+			if err := l.executeComponent(ctx, comp, data, group); err != nil {
 				errChan <- err
 			}
 		}(component)
@@ -182,18 +195,32 @@ func (l *Layer) executeParallel(ctx context.Context, data DataContext) error {
 // executeAsync 异步执行组件
 func (l *Layer) executeAsync(ctx context.Context, data DataContext) error {
 	// 异步执行不等待结果，直接启动所有组件
+	// 后续层可通过 data.Wait/Watch 等待异步组件写入的键
+	group := newCommitGroup()
 	for _, component := range l.components {
 		go func(comp Component) {
-			l.executeComponent(ctx, comp, data)
+			ctx := ctx
+			if l.config.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, l.config.Timeout)
+				defer cancel()
+			}
+			l.executeComponent(ctx, comp, data, group)
 		}(component)
 	}
 	return nil
 }
 
-// executeComponent 执行单个组件
-func (l *Layer) executeComponent(ctx context.Context, component Component, data DataContext) error {
+// executeComponent 执行单个组件，group 用于检测并发组件的事务提交冲突（串行执行时为 nil）
+func (l *Layer) executeComponent(ctx context.Context, component Component, data DataContext, group *commitGroup) error {
 	componentName := component.Name()
 
+	// 组件在 "layer.component" 作用域中执行，写入只对本组件可见，需通过 Export 发布
+	if l.scoped {
+		data = data.Scope(ComponentScopeName(l.config.Name, componentName))
+	}
+	ctx = ContextWithExecutionInfo(ctx, l.executionInfo(componentName, 1))
+
 	// 初始化组件
 	if initComp, ok := component.(InitializableComponent); ok {
 		if err := initComp.Initialize(ctx); err != nil {
@@ -220,9 +247,9 @@ func (l *Layer) executeComponent(ctx context.Context, component Component, data
 	// 执行组件
 	var err error
 	if retryComp, ok := component.(RetryableComponent); ok {
-		err = l.executeWithRetry(ctx, retryComp, data)
+		err = l.executeWithRetry(ctx, retryComp, data, group)
 	} else {
-		err = component.Execute(ctx, data)
+		err = l.executeAttempt(ctx, component, data, group, 1)
 	}
 
 	if err != nil {
@@ -248,7 +275,36 @@ func (l *Layer) executeComponent(ctx context.Context, component Component, data
 	return nil
 }
 
-func (l *Layer) executeWithRetry(ctx context.Context, component RetryableComponent, data DataContext) error {
+// executionInfo 返回组件在本层的执行信息
+func (l *Layer) executionInfo(component string, attempt int) ExecutionInfo {
+	return ExecutionInfo{Layer: l.config.Name, Component: component, Attempt: attempt}
+}
+
+// executeAttempt 执行组件的一次尝试：写入归属于本次尝试（见 ExecutionInfo），
+// 启用事务时写入先缓存在事务上下文中，成功后提交，失败时丢弃
+func (l *Layer) executeAttempt(ctx context.Context, component Component, data DataContext, group *commitGroup, attempt int) error {
+	info := l.executionInfo(component.Name(), attempt)
+	ctx = ContextWithExecutionInfo(ctx, info)
+	data = bindWriter(data, info)
+	if !l.tx {
+		if err := component.Execute(ctx, data); err != nil {
+			return err
+		}
+		// 组件忽略了被拒绝的写入（Set 等没有返回错误）时，本次尝试仍然失败
+		return attemptViolation(data, info)
+	}
+
+	tx := newTxDataContext(data, info, group)
+	if err := component.Execute(ctx, tx); err != nil {
+		return err
+	}
+	if err := tx.commit(); err != nil {
+		return err
+	}
+	return attemptViolation(data, info)
+}
+
+func (l *Layer) executeWithRetry(ctx context.Context, component RetryableComponent, data DataContext, group *commitGroup) error {
 	retryConfig := component.GetRetryConfig()
 	var lastErr error
 	var retryErrors []error
@@ -264,7 +320,7 @@ func (l *Layer) executeWithRetry(ctx context.Context, component RetryableCompone
 			}
 		}
 
-		err := component.Execute(ctx, data)
+		err := l.executeAttempt(ctx, component, data, group, attempt+1)
 		if err == nil {
 			return nil
 		}
@@ -290,7 +346,7 @@ func (l *Layer) executeWithRetry(ctx context.Context, component RetryableCompone
 func (l *Layer) isCriticalComponent(componentName string) bool {
 	for _, config := range l.config.Components {
 		if config.Name == componentName {
-			return config.Critical
+			return config.IsCritical()
 		}
 	}
 	return false
@@ -30,7 +30,7 @@ func TestLayerConfig(t *testing.T) {
 			Components:   []ComponentConfig{},
 			Timeout:      30 * time.Second,
 			Dependencies: []string{"dep1"},
-			Enabled:      true,
+			Enabled:      BoolPtr(true),
 			Parallel:     2,
 		}
 
@@ -43,7 +43,7 @@ func TestLayerConfig(t *testing.T) {
 		if config.Timeout != 30*time.Second {
 			t.Errorf("Expected Timeout to be 30s, got %v", config.Timeout)
 		}
-		if !config.Enabled {
+		if !config.IsEnabled() {
 			t.Error("Expected Enabled to be true")
 		}
 		if config.Parallel != 2 {
@@ -67,10 +67,10 @@ func TestNewLayer(t *testing.T) {
 			Name: "test-layer",
 			Mode: SerialMode,
 			Components: []ComponentConfig{
-				{Name: "comp1", Type: "test-type", Enabled: true},
-				{Name: "comp2", Type: "test-type", Enabled: true},
+				{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
+				{Name: "comp2", Type: "test-type", Enabled: BoolPtr(true)},
 			},
-			Enabled: true,
+			Enabled: BoolPtr(true),
 		}
 
 		layer, err := NewLayer(config, registry)
@@ -90,10 +90,10 @@ func TestNewLayer(t *testing.T) {
 			Name: "test-layer",
 			Mode: SerialMode,
 			Components: []ComponentConfig{
-				{Name: "comp1", Type: "test-type", Enabled: true},
-				{Name: "comp2", Type: "test-type", Enabled: false}, // disabled
+				{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
+				{Name: "comp2", Type: "test-type", Enabled: BoolPtr(false)}, // disabled
 			},
-			Enabled: true,
+			Enabled: BoolPtr(true),
 		}
 
 		layer, err := NewLayer(config, registry)
@@ -110,9 +110,9 @@ func TestNewLayer(t *testing.T) {
 			Name: "test-layer",
 			Mode: SerialMode,
 			Components: []ComponentConfig{
-				{Name: "comp1", Type: "unknown-type", Enabled: true},
+				{Name: "comp1", Type: "unknown-type", Enabled: BoolPtr(true)},
 			},
-			Enabled: true,
+			Enabled: BoolPtr(true),
 		}
 
 		layer, err := NewLayer(config, registry)
@@ -139,9 +139,9 @@ func TestLayerMethods(t *testing.T) {
 		Name: "test-layer",
 		Mode: ParallelMode,
 		Components: []ComponentConfig{
-			{Name: "comp1", Type: "test-type", Enabled: true},
+			{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
 		},
-		Enabled: true,
+		Enabled: BoolPtr(true),
 	}
 
 	layer, _ := NewLayer(config, registry)
@@ -191,17 +191,17 @@ func TestLayerExecute(t *testing.T) {
 				}, nil
 			},
 		}
-		registry.Register(factory)
+		registry.Replace(factory)
 
 		config := LayerConfig{
 			Name: "test-layer",
 			Mode: SerialMode,
 			Components: []ComponentConfig{
-				{Name: "comp1", Type: "test-type", Enabled: true},
-				{Name: "comp2", Type: "test-type", Enabled: true},
-				{Name: "comp3", Type: "test-type", Enabled: true},
+				{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
+				{Name: "comp2", Type: "test-type", Enabled: BoolPtr(true)},
+				{Name: "comp3", Type: "test-type", Enabled: BoolPtr(true)},
 			},
-			Enabled: true,
+			Enabled: BoolPtr(true),
 		}
 
 		layer, _ := NewLayer(config, registry)
@@ -243,17 +243,17 @@ func TestLayerExecute(t *testing.T) {
 				}, nil
 			},
 		}
-		registry.Register(factory)
+		registry.Replace(factory)
 
 		config := LayerConfig{
 			Name: "test-layer",
 			Mode: ParallelMode,
 			Components: []ComponentConfig{
-				{Name: "comp1", Type: "test-type", Enabled: true},
-				{Name: "comp2", Type: "test-type", Enabled: true},
-				{Name: "comp3", Type: "test-type", Enabled: true},
+				{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
+				{Name: "comp2", Type: "test-type", Enabled: BoolPtr(true)},
+				{Name: "comp3", Type: "test-type", Enabled: BoolPtr(true)},
 			},
-			Enabled: true,
+			Enabled: BoolPtr(true),
 		}
 
 		layer, _ := NewLayer(config, registry)
@@ -288,16 +288,16 @@ func TestLayerExecute(t *testing.T) {
 				}, nil
 			},
 		}
-		registry.Register(factory)
+		registry.Replace(factory)
 
 		config := LayerConfig{
 			Name: "test-layer",
 			Mode: AsyncMode,
 			Components: []ComponentConfig{
-				{Name: "comp1", Type: "test-type", Enabled: true},
-				{Name: "comp2", Type: "test-type", Enabled: true},
+				{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
+				{Name: "comp2", Type: "test-type", Enabled: BoolPtr(true)},
 			},
-			Enabled: true,
+			Enabled: BoolPtr(true),
 		}
 
 		layer, _ := NewLayer(config, registry)
@@ -330,17 +330,17 @@ func TestLayerExecute(t *testing.T) {
 				}, nil
 			},
 		}
-		registry.Register(factory)
+		registry.Replace(factory)
 
 		config := LayerConfig{
 			Name: "test-layer",
 			Mode: SerialMode,
 			Components: []ComponentConfig{
-				{Name: "comp1", Type: "test-type", Enabled: true},
-				{Name: "comp2", Type: "test-type", Enabled: true},
-				{Name: "comp3", Type: "test-type", Enabled: true},
+				{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
+				{Name: "comp2", Type: "test-type", Enabled: BoolPtr(true)},
+				{Name: "comp3", Type: "test-type", Enabled: BoolPtr(true)},
 			},
-			Enabled: true,
+			Enabled: BoolPtr(true),
 		}
 
 		layer, _ := NewLayer(config, registry)
@@ -369,16 +369,16 @@ func TestLayerExecute(t *testing.T) {
 				}, nil
 			},
 		}
-		registry.Register(factory)
+		registry.Replace(factory)
 
 		config := LayerConfig{
 			Name:    "test-layer",
 			Mode:    SerialMode,
 			Timeout: 100 * time.Millisecond, // Short timeout
 			Components: []ComponentConfig{
-				{Name: "comp1", Type: "test-type", Enabled: true},
+				{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
 			},
-			Enabled: true,
+			Enabled: BoolPtr(true),
 		}
 
 		layer, _ := NewLayer(config, registry)
@@ -413,10 +413,10 @@ func TestLayerValidate(t *testing.T) {
 			Name: "test-layer",
 			Mode: SerialMode,
 			Components: []ComponentConfig{
-				{Name: "comp1", Type: "test-type", Enabled: true},
-				{Name: "comp2", Type: "test-type", Enabled: true},
+				{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
+				{Name: "comp2", Type: "test-type", Enabled: BoolPtr(true)},
 			},
-			Enabled: true,
+			Enabled: BoolPtr(true),
 		}
 
 		layer, _ := NewLayer(config, registry)
@@ -432,10 +432,10 @@ func TestLayerValidate(t *testing.T) {
 			Name: "test-layer",
 			Mode: SerialMode,
 			Components: []ComponentConfig{
-				{Name: "comp1", Type: "test-type", Enabled: true},
-				{Name: "invalid-comp", Type: "test-type", Enabled: true},
+				{Name: "comp1", Type: "test-type", Enabled: BoolPtr(true)},
+				{Name: "invalid-comp", Type: "test-type", Enabled: BoolPtr(true)},
 			},
-			Enabled: true,
+			Enabled: BoolPtr(true),
 		}
 
 		layer, _ := NewLayer(config, registry)
@@ -450,7 +450,7 @@ func TestLayerValidate(t *testing.T) {
 		config := LayerConfig{
 			Name:    "", // Empty name
 			Mode:    SerialMode,
-			Enabled: true,
+			Enabled: BoolPtr(true),
 		}
 
 		layer, _ := NewLayer(config, registry)
@@ -465,7 +465,7 @@ func TestLayerValidate(t *testing.T) {
 		config := LayerConfig{
 			Name:    "test-layer",
 			Mode:    ExecutionMode("invalid"), // Invalid mode
-			Enabled: true,
+			Enabled: BoolPtr(true),
 		}
 
 		layer, _ := NewLayer(config, registry)
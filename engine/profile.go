@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProfileEnvVar 未通过 WithProfile 指定时，用于选择 profile 的环境变量
+const ProfileEnvVar = "KFLOW_PROFILE"
+
+// ProfileConfig 环境 profile（如 dev/staging/prod）对基础配置的补丁
+// 合并语义与 extends 相同：层与组件按名称新增、修改或通过 remove 删除，global/metadata 按键覆盖
+type ProfileConfig struct {
+	Description string                 `json:"description,omitempty"`
+	Timeout     time.Duration          `json:"timeout,omitempty"`
+	Global      map[string]interface{} `json:"global,omitempty"`
+	Metadata    map[string]string      `json:"metadata,omitempty"`
+	Layers      []LayerConfig          `json:"layers,omitempty"`
+}
+
+// WithProfile 指定解析时应用的 profile，优先于 KFLOW_PROFILE 环境变量
+func WithProfile(name string) ParserOption {
+	return func(p *ConfigParser) {
+		p.profile = name
+	}
+}
+
+// ApplyProfile 返回应用指定 profile 后的有效配置，原配置不变
+// 结果不再包含 profiles，可直接通过 ToJSON 查看该环境实际执行的配置
+func (c *Config) ApplyProfile(name string) (*Config, error) {
+	parser := NewConfigParser()
+	effective, err := parser.applyProfile(c, name)
+	if err != nil {
+		return nil, err
+	}
+	return parser.finalize(effective)
+}
+
+// ProfileNames 返回配置中定义的 profile 名称（已排序）
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectProfile 根据解析器选项或环境变量应用 profile
+// 通过环境变量选择时，未定义任何 profile 的配置保持不变
+func (p *ConfigParser) selectProfile(config *Config) (*Config, error) {
+	name := p.profile
+	if name == "" {
+		name = os.Getenv(ProfileEnvVar)
+		if name == "" || len(config.Profiles) == 0 {
+			return config, nil
+		}
+	}
+	return p.applyProfile(config, name)
+}
+
+// applyProfile 复用 mergeConfigs 将 profile 补丁合并到配置上
+func (p *ConfigParser) applyProfile(config *Config, name string) (*Config, error) {
+	profile, ok := config.Profiles[name]
+	if !ok {
+		available := "none"
+		if names := config.ProfileNames(); len(names) > 0 {
+			available = strings.Join(names, ", ")
+		}
+		return nil, &ConfigError{
+			Type:    "profile_not_found",
+			Field:   "profiles",
+			Message: fmt.Sprintf("profile %q not found (available: %s)", name, available),
+		}
+	}
+
+	patch := &Config{
+		Description: profile.Description,
+		Timeout:     profile.Timeout,
+		Global:      profile.Global,
+		Metadata:    profile.Metadata,
+		Layers:      profile.Layers,
+	}
+	effective, err := p.mergeConfigs(config, patch)
+	if err != nil {
+		return nil, err
+	}
+	effective.Profiles = nil
+	return effective, nil
+}
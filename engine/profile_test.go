@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const profileTestConfig = `{
+	"name": "orders",
+	"timeout": "1m",
+	"global": {"db": "localhost", "batch": 10},
+	"layers": [
+		{"name": "ingest", "components": [
+			{"name": "reader", "type": "reader", "config": {"path": "dev.csv"}},
+			{"name": "sampler", "type": "sampler"}
+		]},
+		{"name": "notify", "components": [{"name": "slack", "type": "notifier"}]}
+	],
+	"profiles": {
+		"dev": {
+			"layers": [{"name": "notify", "enabled": false}]
+		},
+		"prod": {
+			"timeout": "10m",
+			"global": {"db": "prod-db"},
+			"layers": [
+				{"name": "ingest", "mode": "parallel", "components": [
+					{"name": "reader", "config": {"path": "s3://orders"}, "critical": true},
+					{"name": "sampler", "remove": true}
+				]},
+				{"name": "audit", "components": [{"name": "audit_log", "type": "audit"}]}
+			]
+		}
+	}
+}`
+
+func TestConfigProfiles(t *testing.T) {
+	t.Run("No profile selected", func(t *testing.T) {
+		cfg, err := NewConfigParser().ParseBytes([]byte(profileTestConfig))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(cfg.Layers) != 2 || cfg.Global["db"] != "localhost" {
+			t.Errorf("Expected base config, got %+v", cfg)
+		}
+		if names := cfg.ProfileNames(); strings.Join(names, ",") != "dev,prod" {
+			t.Errorf("Expected profiles dev,prod, got %v", names)
+		}
+	})
+
+	t.Run("Select profile via option", func(t *testing.T) {
+		cfg, err := NewConfigParser(WithProfile("prod")).ParseBytes([]byte(profileTestConfig))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.Timeout != 10*time.Minute || cfg.Global["db"] != "prod-db" || cfg.Global["batch"] != 10.0 {
+			t.Errorf("Expected patched globals and timeout, got %v %v", cfg.Timeout, cfg.Global)
+		}
+		ingest := findLayer(cfg, "ingest")
+		if ingest.Mode != ParallelMode || len(ingest.Components) != 1 {
+			t.Fatalf("Expected patched ingest layer, got %+v", ingest)
+		}
+		if reader := ingest.Components[0]; reader.Config["path"] != "s3://orders" || !reader.IsCritical() || reader.Type != "reader" {
+			t.Errorf("Expected patched reader, got %+v", reader)
+		}
+		if findLayer(cfg, "audit") == nil {
+			t.Error("Expected audit layer to be added")
+		}
+		if len(cfg.Profiles) != 0 {
+			t.Error("Effective config should not keep profiles")
+		}
+	})
+
+	t.Run("Select profile via environment", func(t *testing.T) {
+		t.Setenv(ProfileEnvVar, "dev")
+		cfg, err := NewConfigParser().ParseBytes([]byte(profileTestConfig))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if findLayer(cfg, "notify").IsEnabled() {
+			t.Error("Expected notify layer to be disabled in dev")
+		}
+
+		// 未定义 profiles 的配置不受环境变量影响
+		if _, err := NewConfigParser().ParseBytes([]byte(`{"name": "plain", "layers": [{"name": "L1", "components": [{"name": "c", "type": "x"}]}]}`)); err != nil {
+			t.Errorf("Expected config without profiles to parse, got %v", err)
+		}
+	})
+
+	t.Run("Option overrides environment", func(t *testing.T) {
+		t.Setenv(ProfileEnvVar, "dev")
+		cfg, err := NewConfigParser(WithProfile("prod")).ParseBytes([]byte(profileTestConfig))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !findLayer(cfg, "notify").IsEnabled() || findLayer(cfg, "audit") == nil {
+			t.Error("Expected prod profile to be applied")
+		}
+	})
+
+	t.Run("Unknown profile", func(t *testing.T) {
+		_, err := NewConfigParser(WithProfile("qa")).ParseBytes([]byte(profileTestConfig))
+		configErr, ok := err.(*ConfigError)
+		if !ok || configErr.Type != "profile_not_found" {
+			t.Fatalf("Expected profile_not_found, got %v", err)
+		}
+		if !strings.Contains(configErr.Message, "dev, prod") {
+			t.Errorf("Expected available profiles in message, got %s", configErr.Message)
+		}
+	})
+
+	t.Run("ApplyProfile shows effective config", func(t *testing.T) {
+		cfg, err := NewConfigParser().ParseBytes([]byte(profileTestConfig))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		effective, err := cfg.ApplyProfile("prod")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		out, err := effective.ToJSON()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !strings.Contains(out, "s3://orders") || strings.Contains(out, `"profiles"`) {
+			t.Errorf("Unexpected effective config:\n%s", out)
+		}
+		if len(cfg.Profiles) != 2 || len(cfg.Layers) != 2 {
+			t.Error("ApplyProfile should not modify the original config")
+		}
+	})
+}
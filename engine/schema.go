@@ -0,0 +1,463 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// JSONSchemaDraft 生成的 Schema 所使用的 JSON Schema 版本
+const JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Schema JSON Schema 的常用子集
+// 既用于导出工作流文件的 Schema，也用于校验组件的 config 字段
+// Validate 支持 type、properties、required、additionalProperties、items、enum、const、
+// minimum/maximum、minLength/maxLength、pattern、allOf 与 anyOf；$ref 与 if/then 仅用于导出
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 SchemaType         `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // bool 或 *Schema
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Const                interface{}        `json:"const,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	If                   *Schema            `json:"if,omitempty"`
+	Then                 *Schema            `json:"then,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+
+	compiled atomic.Value // *compiledPattern，Pattern 首次校验时编译
+}
+
+// compiledPattern 编译后的 Pattern 及其编译错误
+type compiledPattern struct {
+	source string
+	re     *regexp.Regexp
+	err    error
+}
+
+// patternRegexp 返回编译后的 Pattern，只在首次调用（或 Pattern 被修改后）编译
+func (s *Schema) patternRegexp() (*regexp.Regexp, error) {
+	if cached, ok := s.compiled.Load().(*compiledPattern); ok && cached.source == s.Pattern {
+		return cached.re, cached.err
+	}
+	re, err := regexp.Compile(s.Pattern)
+	s.compiled.Store(&compiledPattern{source: s.Pattern, re: re, err: err})
+	return re, err
+}
+
+// SchemaType JSON Schema 的 type 字段，单一类型序列化为字符串，多个类型序列化为数组
+type SchemaType []string
+
+// MarshalJSON 序列化 type 字段
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON 解析字符串或字符串数组形式的 type 字段
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = SchemaType{single}
+		return nil
+	}
+	var multi []string
+	if err := json.Unmarshal(data, &multi); err != nil {
+		return err
+	}
+	*t = SchemaType(multi)
+	return nil
+}
+
+// ToJSON 将 Schema 转换为 JSON 字符串
+func (s *Schema) ToJSON() (string, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", &ConfigError{
+			Type:    "json_marshal_failed",
+			Message: fmt.Sprintf("failed to marshal schema to JSON: %v", err),
+			Cause:   err,
+		}
+	}
+	return string(data), nil
+}
+
+// GenerateConfigSchema 生成描述工作流配置文件的 JSON Schema
+// 若提供 registry，则实现了 SchemaProvider 的组件工厂所给出的 config Schema
+// 会以 if/then 的形式按组件类型挂载到 ComponentConfig 定义上
+func GenerateConfigSchema(registry *ComponentRegistry) *Schema {
+	definitions := make(map[string]*Schema)
+	root := schemaForType(reflect.TypeOf(Config{}), definitions)
+	root.Schema = JSONSchemaDraft
+	root.Title = "kflow workflow"
+	root.Definitions = definitions
+
+	if registry != nil {
+		component := definitions["ComponentConfig"]
+		for _, typ := range registry.GetRegisteredTypes() {
+			schema, ok := registry.GetSchema(typ)
+			if !ok {
+				continue
+			}
+			name := "config." + typ
+			definitions[name] = schema
+			component.AllOf = append(component.AllOf, &Schema{
+				If: &Schema{
+					Properties: map[string]*Schema{"type": {Const: typ}},
+					Required:   []string{"type"},
+				},
+				Then: &Schema{
+					Properties: map[string]*Schema{"config": {Ref: "#/definitions/" + name}},
+				},
+			})
+		}
+	}
+
+	return root
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	executionModeType   = reflect.TypeOf(ExecutionMode(""))
+	extendsListType     = reflect.TypeOf(ExtendsList{})
+	inputTypeType       = reflect.TypeOf(InputType(""))
+	layerConfigType     = reflect.TypeOf(LayerConfig{})
+	componentConfigType = reflect.TypeOf(ComponentConfig{})
+)
+
+// schemaRequiredFields 各配置结构体中始终必需的字段
+// 继承场景下子配置可以省略大部分字段，因此这里只列出任何情况下都必须提供的字段
+var schemaRequiredFields = map[string][]string{
+	"LayerConfig":     {"name"},
+	"ComponentConfig": {"name"},
+	"InputConfig":     {"name"},
+}
+
+// schemaForType 通过反射为配置类型生成 Schema，结构体类型放入 definitions 并返回引用
+func schemaForType(t reflect.Type, definitions map[string]*Schema) *Schema {
+	switch t {
+	case durationType:
+		return &Schema{
+			Type:        SchemaType{"string", "integer"},
+			Description: `Go duration string (e.g. "5s", "1m30s") or integer nanoseconds`,
+		}
+	case extendsListType:
+		return &Schema{
+			Type:        SchemaType{"string", "array"},
+			Items:       &Schema{Type: SchemaType{"string"}},
+			Description: "parent config path or list of paths, relative to this file, merged in order",
+		}
+	case inputTypeType:
+		return &Schema{
+			Type: SchemaType{"string"},
+			Enum: []interface{}{
+				string(InputTypeAny), string(InputTypeString), string(InputTypeNumber), string(InputTypeInteger),
+				string(InputTypeBoolean), string(InputTypeObject), string(InputTypeArray), string(InputTypeDuration),
+			},
+		}
+	case executionModeType:
+		return &Schema{
+			Type: SchemaType{"string"},
+			Enum: []interface{}{string(SerialMode), string(ParallelMode), string(AsyncMode)},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaForType(t.Elem(), definitions)
+	case reflect.Bool:
+		return &Schema{Type: SchemaType{"boolean"}}
+	case reflect.String:
+		return &Schema{Type: SchemaType{"string"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: SchemaType{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: SchemaType{"number"}}
+	case reflect.Slice, reflect.Array:
+		items := schemaForType(t.Elem(), definitions)
+		if t.Elem() == layerConfigType || t.Elem() == componentConfigType {
+			// layers 与 components 数组（包括 profiles 中的）的元素也可以是 $include 片段引用
+			definitions["Include"] = includeSchema()
+			items = &Schema{AnyOf: []*Schema{items, {Ref: "#/definitions/Include"}}}
+		}
+		return &Schema{Type: SchemaType{"array"}, Items: items}
+	case reflect.Map:
+		schema := &Schema{Type: SchemaType{"object"}}
+		if t.Elem().Kind() != reflect.Interface {
+			schema.AdditionalProperties = schemaForType(t.Elem(), definitions)
+		}
+		return schema
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := definitions[name]; !ok {
+			def := &Schema{
+				Type:                 SchemaType{"object"},
+				Properties:           make(map[string]*Schema),
+				AdditionalProperties: false,
+				Required:             schemaRequiredFields[name],
+			}
+			// 先占位，避免递归类型无限展开
+			definitions[name] = def
+			for field, ft := range jsonFields(t) {
+				def.Properties[field] = schemaForType(ft, definitions)
+			}
+		}
+		if name == "Config" {
+			root := *definitions[name]
+			delete(definitions, name)
+			return &root
+		}
+		return &Schema{Ref: "#/definitions/" + name}
+	}
+
+	return &Schema{}
+}
+
+// includeSchema layers/components 数组中的 $include 片段引用，见 expandIncludes
+func includeSchema() *Schema {
+	return &Schema{
+		Type: SchemaType{"object"},
+		Properties: map[string]*Schema{
+			includeKey:       {Type: SchemaType{"string"}, Description: "fragment file path, relative to this file"},
+			includeParamsKey: {Type: SchemaType{"object"}, Description: "parameters referenced as ${name} in the fragment"},
+		},
+		Required:             []string{includeKey},
+		AdditionalProperties: false,
+	}
+}
+
+// Validate 按 Schema 校验值，path 为错误中报告的字段路径
+// 返回按路径排序的全部校验错误
+func (s *Schema) Validate(value interface{}, path string) []*ValidationError {
+	var errs []*ValidationError
+	s.validate(value, path, &errs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+func (s *Schema) validate(value interface{}, path string, errs *[]*ValidationError) {
+	if s == nil {
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, &ValidationError{Field: path, Value: value, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(s.Type) > 0 && !schemaTypeMatches(s.Type, value) {
+		fail("expected %s, got %s", strings.Join(s.Type, " or "), jsonTypeName(value))
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		matched := false
+		for _, candidate := range s.Enum {
+			if schemaValuesEqual(candidate, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("value must be one of %v", s.Enum)
+		}
+	}
+	if s.Const != nil && !schemaValuesEqual(s.Const, value) {
+		fail("value must be %v", s.Const)
+	}
+
+	if n, ok := toFloat64(value); ok {
+		if s.Minimum != nil && n < *s.Minimum {
+			fail("value must be >= %v", *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			fail("value must be <= %v", *s.Maximum)
+		}
+	}
+
+	if str, ok := value.(string); ok {
+		length := len([]rune(str))
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("length must be >= %d", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("length must be <= %d", *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			re, err := s.patternRegexp()
+			if err != nil {
+				fail("invalid schema pattern %q: %v", s.Pattern, err)
+			} else if !re.MatchString(str) {
+				fail("value must match pattern %q", s.Pattern)
+			}
+		}
+	}
+
+	if obj, ok := toStringMap(value); ok {
+		for _, name := range s.Required {
+			if _, exists := obj[name]; !exists {
+				*errs = append(*errs, &ValidationError{
+					Field:   joinFieldPath(path, name),
+					Message: "required field is missing",
+				})
+			}
+		}
+		for key, v := range obj {
+			fieldPath := joinFieldPath(path, key)
+			if prop, ok := s.Properties[key]; ok {
+				prop.validate(v, fieldPath, errs)
+				continue
+			}
+			switch extra := s.AdditionalProperties.(type) {
+			case bool:
+				if !extra {
+					*errs = append(*errs, &ValidationError{
+						Field:   fieldPath,
+						Value:   v,
+						Message: fmt.Sprintf("unknown field %s", key),
+					})
+				}
+			case *Schema:
+				extra.validate(v, fieldPath, errs)
+			}
+		}
+	}
+
+	if s.Items != nil {
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			for i := 0; i < rv.Len(); i++ {
+				s.Items.validate(rv.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(value, path, errs)
+	}
+
+	if len(s.AnyOf) > 0 {
+		for _, sub := range s.AnyOf {
+			var subErrs []*ValidationError
+			if sub.validate(value, path, &subErrs); len(subErrs) == 0 {
+				return
+			}
+		}
+		fail("value does not match any of the allowed schemas")
+	}
+}
+
+// schemaTypeMatches 判断值是否满足 type 约束
+func schemaTypeMatches(types SchemaType, value interface{}) bool {
+	for _, t := range types {
+		switch t {
+		case "null":
+			if value == nil {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := toFloat64(value); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := toFloat64(value); ok && n == math.Trunc(n) {
+				return true
+			}
+		case "object":
+			if _, ok := toStringMap(value); ok {
+				return true
+			}
+		case "array":
+			if value != nil {
+				kind := reflect.TypeOf(value).Kind()
+				if kind == reflect.Slice || kind == reflect.Array {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// jsonTypeName 返回值对应的 JSON 类型名称，用于错误信息
+func jsonTypeName(value interface{}) string {
+	for _, t := range []string{"null", "boolean", "string", "integer", "number", "object", "array"} {
+		if schemaTypeMatches(SchemaType{t}, value) {
+			return t
+		}
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// schemaValuesEqual 比较 enum/const 的取值，数值按数值比较
+func schemaValuesEqual(a, b interface{}) bool {
+	if na, ok := toFloat64(a); ok {
+		nb, ok := toFloat64(b)
+		return ok && na == nb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// toFloat64 将任意数值类型转换为 float64
+func toFloat64(value interface{}) (float64, bool) {
+	if value == nil {
+		return 0, false
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// toStringMap 将字符串键的 map 转换为 map[string]interface{}
+func toStringMap(value interface{}) (map[string]interface{}, bool) {
+	if m, ok := value.(map[string]interface{}); ok {
+		return m, true
+	}
+	if value == nil {
+		return nil, false
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, false
+	}
+	m := make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		m[iter.Key().String()] = iter.Value().Interface()
+	}
+	return m, true
+}
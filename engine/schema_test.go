@@ -0,0 +1,188 @@
+package engine
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// MockSchemaFactory 提供 config Schema 的模拟组件工厂
+type MockSchemaFactory struct {
+	MockComponentFactory
+	schema *Schema
+}
+
+func (f *MockSchemaFactory) ConfigSchema() *Schema {
+	return f.schema
+}
+
+func newSchemaRegistry() *ComponentRegistry {
+	registry := NewComponentRegistry()
+	registry.Register(&MockSchemaFactory{
+		MockComponentFactory: MockComponentFactory{componentType: "file_reader"},
+		schema: &Schema{
+			Type: SchemaType{"object"},
+			Properties: map[string]*Schema{
+				"file_path": {Type: SchemaType{"string"}},
+				"retries":   {Type: SchemaType{"integer"}},
+			},
+			Required:             []string{"file_path"},
+			AdditionalProperties: false,
+		},
+	})
+	registry.Register(&MockComponentFactory{componentType: "plain"})
+	return registry
+}
+
+func TestGenerateConfigSchema(t *testing.T) {
+	schema := GenerateConfigSchema(newSchemaRegistry())
+
+	if schema.Schema != JSONSchemaDraft {
+		t.Errorf("Expected $schema %s, got %s", JSONSchemaDraft, schema.Schema)
+	}
+	for _, def := range []string{"LayerConfig", "ComponentConfig", "RetryConfig", "config.file_reader"} {
+		if _, ok := schema.Definitions[def]; !ok {
+			t.Errorf("Expected definition %s", def)
+		}
+	}
+	if _, ok := schema.Definitions["config.plain"]; ok {
+		t.Error("Factories without SchemaProvider should not contribute a definition")
+	}
+	// layers/components 的元素可以是配置或 $include 片段引用
+	itemsOf := func(s *Schema) []string {
+		var refs []string
+		for _, branch := range s.Items.AnyOf {
+			refs = append(refs, branch.Ref)
+		}
+		return refs
+	}
+	for name, array := range map[string]*Schema{
+		"layers":            schema.Properties["layers"],
+		"components":        schema.Definitions["LayerConfig"].Properties["components"],
+		"profiles.*.layers": schema.Definitions["ProfileConfig"].Properties["layers"],
+	} {
+		if refs := itemsOf(array); len(refs) != 2 || refs[1] != "#/definitions/Include" {
+			t.Errorf("Expected %s items to accept $include, got %v", name, refs)
+		}
+	}
+	if refs := itemsOf(schema.Properties["layers"]); refs[0] != "#/definitions/LayerConfig" {
+		t.Errorf("Expected layers to reference LayerConfig, got %v", refs)
+	}
+	include := schema.Definitions["Include"]
+	if errs := include.Validate(map[string]interface{}{"$include": "layer.json", "params": map[string]interface{}{"a": 1}}, "layers[0]"); len(errs) != 0 {
+		t.Errorf("Expected include object to be valid, got %v", errs)
+	}
+	if timeout := schema.Properties["timeout"]; len(timeout.Type) != 2 {
+		t.Errorf("Expected timeout to accept string or integer, got %v", timeout.Type)
+	}
+
+	out, err := schema.ToJSON()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var decoded Schema
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("Expected schema JSON to round trip, got %v", err)
+	}
+	if mode := decoded.Definitions["LayerConfig"].Properties["mode"]; len(mode.Enum) != 3 {
+		t.Errorf("Expected mode enum with 3 values, got %v", mode.Enum)
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	min := 1.0
+	schema := &Schema{
+		Type: SchemaType{"object"},
+		Properties: map[string]*Schema{
+			"name":  {Type: SchemaType{"string"}, Pattern: "^[a-z]+$"},
+			"count": {Type: SchemaType{"integer"}, Minimum: &min},
+			"tags":  {Type: SchemaType{"array"}, Items: &Schema{Type: SchemaType{"string"}}},
+			"level": {Enum: []interface{}{"info", "warn"}},
+		},
+		Required:             []string{"name"},
+		AdditionalProperties: false,
+	}
+
+	t.Run("Valid value", func(t *testing.T) {
+		value := map[string]interface{}{"name": "abc", "count": 2.0, "tags": []interface{}{"a"}, "level": "info"}
+		if errs := schema.Validate(value, "config"); len(errs) != 0 {
+			t.Errorf("Expected no errors, got %v", errs)
+		}
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		value := map[string]interface{}{"count": 0.5, "tags": []interface{}{1}, "level": "debug", "extra": true}
+		errs := schema.Validate(value, "config")
+		fields := make([]string, 0, len(errs))
+		for _, err := range errs {
+			fields = append(fields, err.Field)
+		}
+		got := strings.Join(fields, ",")
+		want := "config.count,config.extra,config.level,config.name,config.tags[0]"
+		if got != want {
+			t.Errorf("Expected error fields %s, got %s", want, got)
+		}
+	})
+
+	t.Run("Pattern is compiled once", func(t *testing.T) {
+		name := schema.Properties["name"]
+		name.Validate("abc", "name")
+		cached, ok := name.compiled.Load().(*compiledPattern)
+		if !ok || cached.re == nil {
+			t.Fatal("Expected the compiled pattern to be cached")
+		}
+		name.Validate("ABC", "name")
+		if name.compiled.Load().(*compiledPattern) != cached {
+			t.Error("Expected the cached pattern to be reused")
+		}
+	})
+}
+
+func TestSchemaValidateAnyOf(t *testing.T) {
+	schema := &Schema{AnyOf: []*Schema{
+		{Type: SchemaType{"string"}},
+		{Type: SchemaType{"object"}, Required: []string{"$include"}},
+	}}
+	for _, value := range []interface{}{"a", map[string]interface{}{"$include": "x.json"}} {
+		if errs := schema.Validate(value, "v"); len(errs) != 0 {
+			t.Errorf("Expected %v to match, got %v", value, errs)
+		}
+	}
+	if errs := schema.Validate(map[string]interface{}{}, "v"); len(errs) != 1 || errs[0].Field != "v" {
+		t.Errorf("Expected a single anyOf error, got %v", errs)
+	}
+}
+
+func TestConfigParserSchemaValidation(t *testing.T) {
+	data := `{"name": "schema", "layers": [
+		{"name": "L1", "components": [
+			{"name": "reader", "type": "file_reader", "config": {"file_paht": "a.txt"}},
+			{"name": "other", "type": "plain", "config": {"whatever": 1}}
+		]}
+	]}`
+
+	t.Run("Without schema validation", func(t *testing.T) {
+		if _, err := NewConfigParser().ParseBytes([]byte(data)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("With schema validation", func(t *testing.T) {
+		_, err := NewConfigParser(WithSchemaValidation(newSchemaRegistry())).ParseBytes([]byte(data))
+		errs, ok := err.(ValidationErrors)
+		if !ok {
+			t.Fatalf("Expected ValidationErrors, got %T (%v)", err, err)
+		}
+		// 所有违规一次报告：拼写错误的字段以及因此缺少的必需字段
+		if len(errs) != 2 || errs[0].Field != "layers[0].components[0].config.file_paht" || errs[1].Field != "layers[0].components[0].config.file_path" {
+			t.Errorf("Unexpected errors: %v", errs)
+		}
+	})
+
+	t.Run("Valid component config", func(t *testing.T) {
+		valid := strings.Replace(data, "file_paht", "file_path", 1)
+		if _, err := NewConfigParser(WithSchemaValidation(newSchemaRegistry())).ParseBytes([]byte(valid)); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	})
+}
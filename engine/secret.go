@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SecretRefPrefix 组件配置中引用密钥的前缀，例如 "secret://db_password"
+const SecretRefPrefix = "secret://"
+
+// redactedSecret 密钥在日志与序列化输出中的占位符
+const redactedSecret = "[REDACTED]"
+
+// SecretProvider 密钥提供者接口
+// 组件配置中的 secret:// 引用只在组件工厂创建组件时才会通过它解析，
+// 因此解析后的 Config 以及 ToJSON、Clone 的结果中只包含引用而不包含密钥本身
+// 工厂收到的配置中引用被替换为 Secret 类型而不是 string，config["k"].(string) 对这些字段会失败，
+// 读取可能引用密钥的字段时使用 ConfigString 或 DecodeConfig
+type SecretProvider interface {
+	// GetSecret 根据名称获取密钥
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+// SecretProviderFunc 函数形式的 SecretProvider
+type SecretProviderFunc func(ctx context.Context, name string) (string, error)
+
+// GetSecret 调用函数本身
+func (f SecretProviderFunc) GetSecret(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// Secret 已解析的密钥值
+// 打印（%v/%s/%+v/%#v）与 JSON 序列化时输出 [REDACTED]，通过 Value 获取明文
+// DecodeConfig 可以将 Secret 解码到 string 或 Secret 类型的字段
+type Secret string
+
+// Value 返回密钥明文
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String 返回脱敏后的占位符
+func (s Secret) String() string {
+	return redactedSecret
+}
+
+// GoString 返回脱敏后的占位符
+func (s Secret) GoString() string {
+	return redactedSecret
+}
+
+// MarshalJSON 序列化为脱敏后的占位符
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redactedSecret)
+}
+
+// ConfigString 读取组件配置中的字符串字段，同时接受 string 与解析后的 Secret（返回明文）
+// 字段缺失或不是字符串时返回 false
+func ConfigString(config ComponentConfig, key string) (string, bool) {
+	switch v := config.Config[key].(type) {
+	case string:
+		return v, true
+	case Secret:
+		return v.Value(), true
+	}
+	return "", false
+}
+
+// EnvSecretProvider 从环境变量读取密钥
+// 名称转换为大写，其中的 "-"、"/"、"." 替换为 "_"，并加上 Prefix，
+// 例如 Prefix 为 "KFLOW_SECRET_" 时，db/password 对应 KFLOW_SECRET_DB_PASSWORD
+type EnvSecretProvider struct {
+	Prefix string
+}
+
+// NewEnvSecretProvider 创建基于环境变量的密钥提供者
+func NewEnvSecretProvider(prefix string) *EnvSecretProvider {
+	return &EnvSecretProvider{Prefix: prefix}
+}
+
+// GetSecret 从环境变量读取密钥，变量未设置时返回错误
+func (p *EnvSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	envName := p.Prefix + strings.ToUpper(strings.NewReplacer("-", "_", "/", "_", ".", "_").Replace(name))
+	value, ok := os.LookupEnv(envName)
+	if !ok {
+		return "", fmt.Errorf("secret %s not found: environment variable %s is not set", name, envName)
+	}
+	return value, nil
+}
+
+// FileSecretProvider 从目录中的文件读取密钥（如 Kubernetes/Docker 挂载的 secrets 目录）
+// 文件路径为 Dir/name，读取后去除末尾换行
+type FileSecretProvider struct {
+	Dir string
+}
+
+// NewFileSecretProvider 创建基于文件的密钥提供者
+func NewFileSecretProvider(dir string) *FileSecretProvider {
+	return &FileSecretProvider{Dir: dir}
+}
+
+// GetSecret 读取密钥文件，名称不能跳出 Dir
+func (p *FileSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(name))
+	if name == "" || filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid secret name: %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(p.Dir, cleaned))
+	if err != nil {
+		return "", fmt.Errorf("secret %s not found: %w", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveSecrets 返回解析了 secret:// 引用的组件配置副本，原配置不变
+func resolveSecrets(ctx context.Context, provider SecretProvider, config ComponentConfig) (ComponentConfig, error) {
+	if config.Config == nil {
+		return config, nil
+	}
+	resolved, err := resolveSecretValue(ctx, provider, config.Config, "config")
+	if err != nil {
+		return config, &ComponentError{
+			Type:      "secret_resolution_failed",
+			Message:   err.Error(),
+			Component: config.Name,
+			Cause:     err,
+		}
+	}
+	config.Config = resolved.(map[string]interface{})
+	return config, nil
+}
+
+// resolveSecretValue 递归复制配置值并将 secret:// 引用替换为 Secret
+func resolveSecretValue(ctx context.Context, provider SecretProvider, value interface{}, path string) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.HasPrefix(v, SecretRefPrefix) {
+			return v, nil
+		}
+		name := strings.TrimPrefix(v, SecretRefPrefix)
+		if provider == nil {
+			return nil, fmt.Errorf("%s references secret %s but no secret provider is configured", path, name)
+		}
+		secret, err := provider.GetSecret(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+		return Secret(secret), nil
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			resolved, err := resolveSecretValue(ctx, provider, item, joinFieldPath(path, k))
+			if err != nil {
+				return nil, err
+			}
+			copied[k] = resolved
+		}
+		return copied, nil
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := resolveSecretValue(ctx, provider, item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			copied[i] = resolved
+		}
+		return copied, nil
+	}
+	return value, nil
+}
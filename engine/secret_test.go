@@ -0,0 +1,137 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecret(t *testing.T) {
+	secret := Secret("hunter2")
+	if secret.Value() != "hunter2" {
+		t.Errorf("Expected plain value, got %s", secret.Value())
+	}
+	for _, format := range []string{"%v", "%s", "%+v", "%#v"} {
+		if out := fmt.Sprintf(format, map[string]interface{}{"password": secret}); strings.Contains(out, "hunter2") {
+			t.Errorf("Secret leaked with %s: %s", format, out)
+		}
+	}
+	data, err := json.Marshal(map[string]interface{}{"password": secret})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(data) != `{"password":"[REDACTED]"}` {
+		t.Errorf("Expected redacted JSON, got %s", data)
+	}
+}
+
+func TestSecretProviders(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Environment provider", func(t *testing.T) {
+		t.Setenv("KFLOW_SECRET_DB_PASSWORD", "pw")
+		provider := NewEnvSecretProvider("KFLOW_SECRET_")
+		if v, err := provider.GetSecret(ctx, "db/password"); err != nil || v != "pw" {
+			t.Errorf("Expected pw, got %q (%v)", v, err)
+		}
+		if _, err := provider.GetSecret(ctx, "missing"); err == nil {
+			t.Error("Expected error for missing secret")
+		}
+	})
+
+	t.Run("File provider", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dir, "db"), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "db", "password"), []byte("pw\n"), 0o600); err != nil {
+			t.Fatalf("write secret: %v", err)
+		}
+		provider := NewFileSecretProvider(dir)
+		if v, err := provider.GetSecret(ctx, "db/password"); err != nil || v != "pw" {
+			t.Errorf("Expected pw, got %q (%v)", v, err)
+		}
+		if _, err := provider.GetSecret(ctx, "../etc/passwd"); err == nil {
+			t.Error("Expected error for path escaping the secrets directory")
+		}
+	})
+}
+
+func TestRegistryResolvesSecrets(t *testing.T) {
+	data := `{"name": "secrets", "layers": [{"name": "L1", "components": [
+		{"name": "db", "type": "db", "config": {"dsn": "postgres://app", "password": "secret://db_password", "replicas": [{"token": "secret://replica"}]}}
+	]}]}`
+	cfg, err := NewConfigParser().ParseBytes([]byte(data))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	out, _ := cfg.ToJSON()
+	if !strings.Contains(out, "secret://db_password") {
+		t.Errorf("Parsed config should keep the secret reference, got:\n%s", out)
+	}
+
+	var received ComponentConfig
+	registry := NewComponentRegistry()
+	registry.Register(&MockComponentFactory{
+		componentType: "db",
+		createFunc: func(config ComponentConfig) (Component, error) {
+			received = config
+			return &MockComponent{name: config.Name}, nil
+		},
+	})
+
+	componentConfig := cfg.Layers[0].Components[0]
+
+	t.Run("Without provider", func(t *testing.T) {
+		_, err := registry.Create(componentConfig)
+		var componentErr *ComponentError
+		if !errors.As(err, &componentErr) || componentErr.Type != "secret_resolution_failed" {
+			t.Fatalf("Expected secret_resolution_failed, got %v", err)
+		}
+	})
+
+	t.Run("With provider", func(t *testing.T) {
+		registry.SetSecretProvider(SecretProviderFunc(func(ctx context.Context, name string) (string, error) {
+			return "value-of-" + name, nil
+		}))
+		if _, err := registry.Create(componentConfig); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		password, ok := received.Config["password"].(Secret)
+		if !ok || password.Value() != "value-of-db_password" {
+			t.Errorf("Expected resolved secret, got %#v", received.Config["password"])
+		}
+		replica := received.Config["replicas"].([]interface{})[0].(map[string]interface{})
+		if token, ok := replica["token"].(Secret); !ok || token.Value() != "value-of-replica" {
+			t.Errorf("Expected nested secret to be resolved, got %v", replica["token"])
+		}
+		if received.Config["dsn"] != "postgres://app" {
+			t.Errorf("Expected plain values to be kept, got %v", received.Config["dsn"])
+		}
+		if componentConfig.Config["password"] != "secret://db_password" {
+			t.Error("Resolving secrets should not modify the parsed config")
+		}
+
+		type dbConfig struct {
+			Password string `config:"password,required"`
+		}
+		decoded, err := DecodeConfig[dbConfig](received)
+		if err != nil || decoded.Password != "value-of-db_password" {
+			t.Errorf("Expected DecodeConfig to unwrap secret, got %q (%v)", decoded.Password, err)
+		}
+		if password, ok := ConfigString(received, "password"); !ok || password != "value-of-db_password" {
+			t.Errorf("Expected ConfigString to unwrap secret, got %q", password)
+		}
+		if dsn, ok := ConfigString(received, "dsn"); !ok || dsn != "postgres://app" {
+			t.Errorf("Expected ConfigString to read plain strings, got %q", dsn)
+		}
+		if _, ok := ConfigString(received, "replicas"); ok {
+			t.Error("Expected ConfigString to reject non-string values")
+		}
+	})
+}
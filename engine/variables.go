@@ -0,0 +1,409 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VariableSource 配置变量插值的取值来源
+type VariableSource interface {
+	// Lookup 查找变量，ok 为 false 表示变量未定义（空字符串视为已定义）
+	Lookup(name string) (value string, ok bool)
+}
+
+// VariableSourceFunc 函数形式的 VariableSource
+type VariableSourceFunc func(name string) (string, bool)
+
+// Lookup 调用函数本身
+func (f VariableSourceFunc) Lookup(name string) (string, bool) {
+	return f(name)
+}
+
+// EnvSource 从进程环境变量取值，解析器默认使用该来源
+func EnvSource() VariableSource {
+	return VariableSourceFunc(os.LookupEnv)
+}
+
+// MapSource 从 map 中取值，常用于测试或由调用方注入参数
+func MapSource(values map[string]string) VariableSource {
+	return VariableSourceFunc(func(name string) (string, bool) {
+		v, ok := values[name]
+		return v, ok
+	})
+}
+
+// FileSource 从 KEY=VALUE 格式的文件（如 .env）中取值
+// 空行与 # 开头的行会被忽略，值两侧的引号会被去除
+func FileSource(path string) (VariableSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &ConfigError{
+			Type:    "variable_source_failed",
+			Message: fmt.Sprintf("failed to read variable file: %v", err),
+			Cause:   err,
+		}
+	}
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, &ConfigError{
+				Type:    "variable_source_failed",
+				Message: fmt.Sprintf("invalid line %d in variable file %s: expected KEY=VALUE", lineNo, path),
+			}
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return MapSource(values), nil
+}
+
+// DirSource 从目录中取值：文件名为变量名，文件内容（去除末尾换行）为变量值
+// 适用于 Kubernetes/Docker 挂载的配置目录
+func DirSource(dir string) VariableSource {
+	return VariableSourceFunc(func(name string) (string, bool) {
+		if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+			return "", false
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return "", false
+		}
+		return strings.TrimRight(string(data), "\r\n"), true
+	})
+}
+
+// ChainSources 依次查询多个来源，返回第一个已定义的值
+func ChainSources(sources ...VariableSource) VariableSource {
+	return VariableSourceFunc(func(name string) (string, bool) {
+		for _, source := range sources {
+			if v, ok := source.Lookup(name); ok {
+				return v, true
+			}
+		}
+		return "", false
+	})
+}
+
+// WithVariableSource 设置配置插值的变量来源，多个来源按顺序查询
+func WithVariableSource(sources ...VariableSource) ParserOption {
+	return func(p *ConfigParser) {
+		if len(sources) == 1 {
+			p.variables = sources[0]
+			return
+		}
+		p.variables = ChainSources(sources...)
+	}
+}
+
+// interpolateJSON 解析 JSON 并对其中的字符串值进行变量插值
+// 插值作用于解析后的字符串值，因此变量值中的引号、换行等字符不会破坏 JSON 结构
+func (p *ConfigParser) interpolateJSON(data []byte) (interface{}, error) {
+	raw, err := decodeRawJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	if raw, err = coerceReferences(p.variables, nil, raw, reflect.TypeOf(Config{}), ""); err != nil {
+		return nil, err
+	}
+	return interpolateValue(p.variables, raw, "")
+}
+
+// coerceReferences 按目标类型 t 遍历 value：数值与布尔字段中恰好为单个 ${name} 引用的字符串
+// 解析为对应的 JSON 类型，使 "max_retries": "${RETRIES}" 这类写法得到数值而不是字符串
+// typed 中的参数由 interpolateTyped 按原 JSON 类型替换，这里跳过
+func coerceReferences(source VariableSource, typed map[string]interface{}, value interface{}, t reflect.Type, path string) (interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch v := value.(type) {
+	case string:
+		return coerceReference(source, typed, v, t, path)
+	case map[string]interface{}:
+		if t.Kind() != reflect.Struct && t.Kind() != reflect.Map {
+			return value, nil
+		}
+		var fields map[string]reflect.Type
+		if t.Kind() == reflect.Struct {
+			fields = jsonFields(t)
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			ft, ok := fields[key]
+			if t.Kind() == reflect.Map {
+				ft, ok = t.Elem(), true
+			}
+			if !ok {
+				continue
+			}
+			replaced, err := coerceReferences(source, typed, v[key], ft, joinFieldPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+			v[key] = replaced
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return value, nil
+		}
+		for i, item := range v {
+			replaced, err := coerceReferences(source, typed, item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = replaced
+		}
+	}
+	return value, nil
+}
+
+// coerceReference 解析单个字符串值，非数值、布尔字段或不是单个引用时原样返回
+func coerceReference(source VariableSource, typed map[string]interface{}, s string, t reflect.Type, path string) (interface{}, error) {
+	var expected string
+	switch t.Kind() {
+	case reflect.Bool:
+		expected = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// 时长同时接受字符串，保持原样
+		if t == durationType {
+			return s, nil
+		}
+		expected = "integer"
+	case reflect.Float32, reflect.Float64:
+		expected = "number"
+	default:
+		return s, nil
+	}
+	name, ok := wholeReference(s)
+	if !ok {
+		return s, nil
+	}
+	if _, ok := typed[name]; ok {
+		return s, nil
+	}
+
+	resolved, err := interpolateString(source, s, path)
+	if err != nil {
+		return nil, err
+	}
+	if expected == "boolean" {
+		if b, err := strconv.ParseBool(resolved); err == nil {
+			return b, nil
+		}
+	} else if _, err := strconv.ParseFloat(resolved, 64); err == nil {
+		return json.Number(resolved), nil
+	}
+	return nil, &ConfigError{
+		Type:    "invalid_variable_value",
+		Field:   path,
+		Message: fmt.Sprintf("%s resolves to %q, expected %s", s, resolved, expected),
+	}
+}
+
+// wholeReference 判断 s 是否恰好为单个 ${name} 引用（可带 :default 等修饰），返回变量名
+func wholeReference(s string) (string, bool) {
+	if !strings.HasPrefix(s, "${") || !strings.HasSuffix(s, "}") {
+		return "", false
+	}
+	expr := s[2 : len(s)-1]
+	if strings.ContainsAny(expr, "{}") {
+		return "", false
+	}
+	name, _, _ := strings.Cut(expr, ":")
+	return name, true
+}
+
+// decodeRawJSON 将 JSON 解析为通用结构，数值保留为 json.Number 以避免精度损失
+func decodeRawJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, &ConfigError{
+			Type:    "json_unmarshal_failed",
+			Message: fmt.Sprintf("failed to unmarshal JSON config: %v", err),
+			Cause:   err,
+		}
+	}
+	return raw, nil
+}
+
+// interpolateValue 使用给定的变量来源递归替换字符串值中的变量引用
+func interpolateValue(source VariableSource, value interface{}, path string) (interface{}, error) {
+	return interpolateTyped(source, nil, value, path)
+}
+
+// interpolateTyped 与 interpolateValue 相同，但字符串恰好是单个 ${name} 引用且 typed 中有 name 时，
+// 整个值替换为 typed 中的值（的副本），保留数值、布尔、null、对象与数组的 JSON 类型
+func interpolateTyped(source VariableSource, typed map[string]interface{}, value interface{}, path string) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if replaced, ok := typedReference(typed, v); ok {
+			return replaced, nil
+		}
+		return interpolateString(source, v, path)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			replaced, err := interpolateTyped(source, typed, v[k], joinFieldPath(path, k))
+			if err != nil {
+				return nil, err
+			}
+			v[k] = replaced
+		}
+		return v, nil
+	case []interface{}:
+		for i, item := range v {
+			replaced, err := interpolateTyped(source, typed, item, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = replaced
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+// typedReference 判断 s 是否为单个引用 typed 中的值的 ${name}（可带 :default 等修饰），返回值的副本
+func typedReference(typed map[string]interface{}, s string) (interface{}, bool) {
+	if len(typed) == 0 {
+		return nil, false
+	}
+	name, ok := wholeReference(s)
+	if !ok {
+		return nil, false
+	}
+	value, ok := typed[name]
+	if !ok {
+		return nil, false
+	}
+	return copyRawValue(value), true
+}
+
+// copyRawValue 深拷贝 JSON 解析出的通用结构，避免同一参数替换到多处后共享 map 与切片
+func copyRawValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			copied[k] = copyRawValue(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyRawValue(item)
+		}
+		return copied
+	}
+	return value
+}
+
+// interpolateString 替换字符串中的变量引用，支持以下语法：
+//
+//	${VAR}            变量未定义时报错
+//	${VAR:default}    变量未定义时使用默认值（已定义但为空时保留空值）
+//	${VAR:?message}   变量未定义时以 message 报错
+//	$${               转义为字面量 ${
+func interpolateString(source VariableSource, s, path string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "$${") {
+			b.WriteString("${")
+			i += 3
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "${") {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(s[i+2:], '}')
+		if end < 0 {
+			return "", &ConfigError{
+				Type:    "invalid_variable_reference",
+				Field:   path,
+				Message: fmt.Sprintf("unterminated variable reference in %q", s),
+			}
+		}
+		expr := s[i+2 : i+2+end]
+		value, err := resolveVariable(source, expr, path)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(value)
+		i += end + 3
+	}
+	return b.String(), nil
+}
+
+// resolveVariable 解析单个变量表达式
+func resolveVariable(source VariableSource, expr, path string) (string, error) {
+	name, rest, hasModifier := strings.Cut(expr, ":")
+	if name == "" {
+		return "", &ConfigError{
+			Type:    "invalid_variable_reference",
+			Field:   path,
+			Message: fmt.Sprintf("empty variable name in ${%s}", expr),
+		}
+	}
+
+	if value, ok := source.Lookup(name); ok {
+		return value, nil
+	}
+
+	switch {
+	case hasModifier && strings.HasPrefix(rest, "?"):
+		message := strings.TrimPrefix(rest, "?")
+		if message == "" {
+			message = "required variable is not set"
+		}
+		return "", &ConfigError{
+			Type:    "required_variable_missing",
+			Field:   path,
+			Message: fmt.Sprintf("%s: %s", name, message),
+		}
+	case hasModifier:
+		return rest, nil
+	default:
+		return "", &ConfigError{
+			Type:    "undefined_variable",
+			Field:   path,
+			Message: fmt.Sprintf("variable %s is not set and has no default (use ${%s:default})", name, name),
+		}
+	}
+}
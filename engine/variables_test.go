@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func parseWithVariables(t *testing.T, data string, sources ...VariableSource) (*Config, error) {
+	t.Helper()
+	return NewConfigParser(WithVariableSource(sources...)).ParseBytes([]byte(data))
+}
+
+func TestConfigVariableInterpolation(t *testing.T) {
+	t.Run("Values with quotes and newlines", func(t *testing.T) {
+		cfg, err := parseWithVariables(t, `{"name": "${NAME}", "layers": [
+			{"name": "L1", "components": [{"name": "C1", "type": "X", "config": {"message": "say: ${MSG}"}}]}
+		]}`, MapSource(map[string]string{"NAME": "vars", "MSG": "\"hi\"\nthere"}))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := cfg.Layers[0].Components[0].Config["message"]; got != "say: \"hi\"\nthere" {
+			t.Errorf("Unexpected interpolated value: %q", got)
+		}
+	})
+
+	t.Run("Empty variable is not treated as unset", func(t *testing.T) {
+		cfg, err := parseWithVariables(t, `{"name": "vars", "description": "${DESC:fallback}", "layers": [
+			{"name": "L1", "components": [{"name": "C1", "type": "X"}]}
+		]}`, MapSource(map[string]string{"DESC": ""}))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.Description != "" {
+			t.Errorf("Expected empty description, got %q", cfg.Description)
+		}
+	})
+
+	t.Run("Defaults and escaping", func(t *testing.T) {
+		cfg, err := parseWithVariables(t, `{"name": "vars", "description": "$${LITERAL} ${MISSING:default}", "timeout": "${TIMEOUT:30s}", "layers": [
+			{"name": "L1", "components": [{"name": "C1", "type": "X"}]}
+		]}`, MapSource(nil))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.Description != "${LITERAL} default" {
+			t.Errorf("Unexpected description: %q", cfg.Description)
+		}
+		if cfg.Timeout.String() != "30s" {
+			t.Errorf("Expected interpolated timeout 30s, got %v", cfg.Timeout)
+		}
+	})
+
+	t.Run("Required variable", func(t *testing.T) {
+		_, err := parseWithVariables(t, `{"name": "vars", "layers": [
+			{"name": "L1", "components": [{"name": "C1", "type": "X", "config": {"token": "${API_TOKEN:?set API_TOKEN to the service token}"}}]}
+		]}`, MapSource(nil))
+		configErr, ok := err.(*ConfigError)
+		if !ok || configErr.Type != "required_variable_missing" {
+			t.Fatalf("Expected required_variable_missing, got %v", err)
+		}
+		if configErr.Field != "layers[0].components[0].config.token" {
+			t.Errorf("Unexpected field: %s", configErr.Field)
+		}
+		if configErr.Message != "API_TOKEN: set API_TOKEN to the service token" {
+			t.Errorf("Unexpected message: %s", configErr.Message)
+		}
+	})
+
+	t.Run("Undefined variable without default", func(t *testing.T) {
+		_, err := parseWithVariables(t, `{"name": "${UNDEFINED}", "layers": []}`, MapSource(nil))
+		if configErr, ok := err.(*ConfigError); !ok || configErr.Type != "undefined_variable" || configErr.Field != "name" {
+			t.Fatalf("Expected undefined_variable on name, got %v", err)
+		}
+	})
+
+	t.Run("Unterminated reference", func(t *testing.T) {
+		_, err := parseWithVariables(t, `{"name": "${OPEN", "layers": []}`, MapSource(nil))
+		if configErr, ok := err.(*ConfigError); !ok || configErr.Type != "invalid_variable_reference" {
+			t.Fatalf("Expected invalid_variable_reference, got %v", err)
+		}
+	})
+
+	t.Run("Whole references in numeric and boolean fields", func(t *testing.T) {
+		vars := MapSource(map[string]string{"PAR": "4", "N": "3", "B": "1.5", "ON": "false"})
+		cfg, err := parseWithVariables(t, `{"name": "vars", "layers": [
+			{"name": "L1", "parallel": "${PAR}", "components": [
+				{"name": "C1", "type": "X", "enabled": "${ON}", "retry": {"max_retries": "${N}", "backoff": "${B}", "delay": "${DELAY:1s}"}}
+			]}
+		]}`, vars)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		component := cfg.Layers[0].Components[0]
+		if cfg.Layers[0].Parallel != 4 || component.IsEnabled() || component.Retry.MaxRetries != 3 || component.Retry.Backoff != 1.5 {
+			t.Errorf("Unexpected coerced values: parallel %d, component %+v, retry %+v", cfg.Layers[0].Parallel, component, component.Retry)
+		}
+
+		_, err = parseWithVariables(t, `{"name": "vars", "layers": [{"name": "L1", "parallel": "${PAR}", "components": []}]}`,
+			MapSource(map[string]string{"PAR": "many"}))
+		if configErr, ok := err.(*ConfigError); !ok || configErr.Type != "invalid_variable_value" || configErr.Field != "layers[0].parallel" {
+			t.Fatalf("Expected invalid_variable_value on layers[0].parallel, got %v", err)
+		}
+	})
+
+	t.Run("Environment source by default", func(t *testing.T) {
+		t.Setenv("KFLOW_TEST_NAME", "from-env")
+		cfg, err := NewConfigParser().ParseBytes([]byte(`{"name": "${KFLOW_TEST_NAME}", "layers": [
+			{"name": "L1", "components": [{"name": "C1", "type": "X"}]}
+		]}`))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.Name != "from-env" {
+			t.Errorf("Expected name from env, got %s", cfg.Name)
+		}
+	})
+}
+
+func TestVariableSources(t *testing.T) {
+	dir := t.TempDir()
+
+	envFile := filepath.Join(dir, "vars.env")
+	content := "# comment\nexport HOST=db.local\nPORT = \"5432\"\nEMPTY=\n"
+	if err := os.WriteFile(envFile, []byte(content), 0o644); err != nil {
+		t.Fatalf("write env file: %v", err)
+	}
+	fileSource, err := FileSource(envFile)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	secretsDir := filepath.Join(dir, "secrets")
+	if err := os.Mkdir(secretsDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(secretsDir, "TOKEN"), []byte("s3cr3t\n"), 0o600); err != nil {
+		t.Fatalf("write secret: %v", err)
+	}
+
+	source := ChainSources(MapSource(map[string]string{"HOST": "override"}), fileSource, DirSource(secretsDir))
+	cases := []struct {
+		name  string
+		value string
+		ok    bool
+	}{
+		{"HOST", "override", true},
+		{"PORT", "5432", true},
+		{"EMPTY", "", true},
+		{"TOKEN", "s3cr3t", true},
+		{"../vars.env", "", false},
+		{"MISSING", "", false},
+	}
+	for _, c := range cases {
+		value, ok := source.Lookup(c.name)
+		if value != c.value || ok != c.ok {
+			t.Errorf("Lookup(%s) = %q, %v; expected %q, %v", c.name, value, ok, c.value, c.ok)
+		}
+	}
+
+	if err := os.WriteFile(envFile, []byte("NOT A PAIR\n"), 0o644); err != nil {
+		t.Fatalf("rewrite env file: %v", err)
+	}
+	if _, err := FileSource(envFile); err == nil {
+		t.Error("Expected error for malformed variable file")
+	}
+}
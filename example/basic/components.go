@@ -204,19 +204,14 @@ func (c *FileWriterComponent) Name() string {
 	return c.name
 }
 
-// LoggerComponent 日志组件
+// LoggerComponent 日志组件，通过 engine.RegisterType 注册：config 直接解码到结构体，
+// 不需要单独的工厂类型与 Name 方法（名称取自配置）
 type LoggerComponent struct {
-	name    string
-	level   string
-	message string
-	isCore  bool
+	Level   string `config:"level" default:"info"`
+	Message string `config:"message"`
 }
 
 func (c *LoggerComponent) Execute(ctx context.Context, data engine.DataContext) error {
-	fmt.Printf("[%s] %s: %s\n", c.level, time.Now().Format("2006-01-02 15:04:05"), c.message)
+	fmt.Printf("[%s] %s: %s\n", c.Level, time.Now().Format("2006-01-02 15:04:05"), c.Message)
 	return nil
-}
-
-func (c *LoggerComponent) Name() string {
-	return c.name
 }
\ No newline at end of file
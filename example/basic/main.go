@@ -15,10 +15,12 @@ func main() {
 	registry := engine.NewComponentRegistry()
 
 	// 注册组件工厂
-	registerComponentFactories(registry)
+	if err := registerComponentFactories(registry); err != nil {
+		log.Fatalf("注册组件失败: %v", err)
+	}
 
-	// 从配置文件加载
-	parser := engine.NewConfigParser()
+	// 从配置文件加载（严格模式：拒绝未知或废弃字段，并按组件 Schema 校验 config）
+	parser := engine.NewConfigParser(engine.WithStrictMode(), engine.WithSchemaValidation(registry))
 	var config *engine.Config
 	var err error
 	for _, p := range []string{"workflow.json", "example/basic/workflow.json"} {
@@ -70,15 +72,23 @@ func main() {
 // 定义组件工厂类型
 type fileReaderFactory struct{}
 
+// fileReaderConfig file_reader 组件的类型化配置
+type fileReaderConfig struct {
+	FilePath string `config:"file_path,required"`
+	Encoding string `config:"encoding" default:"utf-8"`
+}
+
 func (f *fileReaderFactory) Create(config engine.ComponentConfig) (engine.Component, error) {
-	filePath, _ := config.Config["file_path"].(string)
-	encoding, _ := config.Config["encoding"].(string)
+	cfg, err := engine.DecodeConfig[fileReaderConfig](config)
+	if err != nil {
+		return nil, err
+	}
 
 	return &FileReaderComponent{
 		name:     config.Name,
-		filePath: filePath,
-		encoding: encoding,
-		isCore:   config.Critical,
+		filePath: cfg.FilePath,
+		encoding: cfg.Encoding,
+		isCore:   config.IsCritical(),
 	}, nil
 }
 
@@ -86,15 +96,29 @@ func (f *fileReaderFactory) GetType() string {
 	return "file_reader"
 }
 
+// ConfigSchema 声明 file_reader 的 config 字段，拼写错误会在解析配置时报错
+func (f *fileReaderFactory) ConfigSchema() *engine.Schema {
+	return &engine.Schema{
+		Type: engine.SchemaType{"object"},
+		Properties: map[string]*engine.Schema{
+			"file_path": {Type: engine.SchemaType{"string"}},
+			"encoding":  {Type: engine.SchemaType{"string"}, Enum: []interface{}{"utf-8", "gbk"}},
+		},
+		Required:             []string{"file_path"},
+		AdditionalProperties: false,
+	}
+}
+
 type configReaderFactory struct{}
 
 func (f *configReaderFactory) Create(config engine.ComponentConfig) (engine.Component, error) {
-	configPath, _ := config.Config["config_path"].(string)
+	// ConfigString 同时接受普通字符串与 secret:// 引用解析出的 Secret
+	configPath, _ := engine.ConfigString(config, "config_path")
 
 	return &ConfigReaderComponent{
 		name:       config.Name,
 		configPath: configPath,
-		isCore:     config.Critical,
+		isCore:     config.IsCritical(),
 	}, nil
 }
 
@@ -117,7 +141,7 @@ func (f *transformerFactory) Create(config engine.ComponentConfig) (engine.Compo
 	return &TransformerComponent{
 		name:       config.Name,
 		operations: operations,
-		isCore:     config.Critical,
+		isCore:     config.IsCritical(),
 	}, nil
 }
 
@@ -140,7 +164,7 @@ func (f *validatorFactory) Create(config engine.ComponentConfig) (engine.Compone
 	return &ValidatorComponent{
 		name:   config.Name,
 		rules:  rules,
-		isCore: config.Critical,
+		isCore: config.IsCritical(),
 	}, nil
 }
 
@@ -150,15 +174,23 @@ func (f *validatorFactory) GetType() string {
 
 type fileWriterFactory struct{}
 
+// fileWriterConfig file_writer 组件的类型化配置
+type fileWriterConfig struct {
+	OutputPath string `config:"output_path,required"`
+	Append     bool   `config:"append"`
+}
+
 func (f *fileWriterFactory) Create(config engine.ComponentConfig) (engine.Component, error) {
-	outputPath, _ := config.Config["output_path"].(string)
-	appendMode, _ := config.Config["append"].(bool)
+	cfg, err := engine.DecodeConfig[fileWriterConfig](config)
+	if err != nil {
+		return nil, err
+	}
 
 	return &FileWriterComponent{
 		name:       config.Name,
-		outputPath: outputPath,
-		append:     appendMode,
-		isCore:     config.Critical,
+		outputPath: cfg.OutputPath,
+		append:     cfg.Append,
+		isCore:     config.IsCritical(),
 	}, nil
 }
 
@@ -166,30 +198,19 @@ func (f *fileWriterFactory) GetType() string {
 	return "file_writer"
 }
 
-type loggerFactory struct{}
-
-func (f *loggerFactory) Create(config engine.ComponentConfig) (engine.Component, error) {
-	level, _ := config.Config["level"].(string)
-	message, _ := config.Config["message"].(string)
-
-	return &LoggerComponent{
-		name:    config.Name,
-		level:   level,
-		message: message,
-		isCore:  config.Critical,
-	}, nil
-}
-
-func (f *loggerFactory) GetType() string {
-	return "logger"
-}
-
-// 注册组件工厂
-func registerComponentFactories(registry *engine.ComponentRegistry) {
-	registry.Register(&fileReaderFactory{})
-	registry.Register(&configReaderFactory{})
-	registry.Register(&transformerFactory{})
-	registry.Register(&validatorFactory{})
-	registry.Register(&fileWriterFactory{})
-	registry.Register(&loggerFactory{})
+// 注册组件工厂，同一类型重复注册时返回错误
+func registerComponentFactories(registry *engine.ComponentRegistry) error {
+	factories := []engine.ComponentFactory{
+		&fileReaderFactory{},
+		&configReaderFactory{},
+		&transformerFactory{},
+		&validatorFactory{},
+		&fileWriterFactory{},
+	}
+	for _, factory := range factories {
+		if err := registry.Register(factory); err != nil {
+			return err
+		}
+	}
+	return engine.RegisterType[LoggerComponent](registry, "logger")
 }
\ No newline at end of file
@@ -12,9 +12,24 @@ type (
 	CleanupComponent      = engine.CleanupComponent
 	RetryableComponent    = engine.RetryableComponent
 	ValidatableComponent  = engine.ValidatableComponent
+	DataFlowComponent     = engine.DataFlowComponent
+	DataFlow              = engine.DataFlow
 
 	// Data context
-	DataContext = engine.DataContext
+	DataContext   = engine.DataContext
+	ScopeSnapshot = engine.ScopeSnapshot
+	Change        = engine.Change
+	WriteOp       = engine.WriteOp
+	WriteRecord   = engine.WriteRecord
+	ExecutionInfo = engine.ExecutionInfo
+	TrackedDataContext = engine.TrackedDataContext
+	DataCodec          = engine.DataCodec
+	BlobStore          = engine.BlobStore
+	Blob               = engine.Blob
+	DataTypeRegistry   = engine.DataTypeRegistry
+	SerializeOption    = engine.SerializeOption
+	DataSchemaRegistry = engine.DataSchemaRegistry
+	DataSchemaMode     = engine.DataSchemaMode
 
 	// Configuration types
 	ComponentConfig = engine.ComponentConfig
@@ -33,6 +48,7 @@ type (
 	Logger         = engine.Logger
 	ErrorHandler   = engine.ErrorHandler
 	Middleware     = engine.Middleware
+	DataChangeMiddleware = engine.DataChangeMiddleware
 
 	// Layer types
 	Layer         = engine.Layer
@@ -46,9 +62,34 @@ type (
 	RetryExhaustedError  = engine.RetryExhaustedError
 	CriticalComponentError = engine.CriticalComponentError
 	ValidationError      = engine.ValidationError
+	ValidationErrors     = engine.ValidationErrors
+	DataContextError     = engine.DataContextError
 
 	// Parser type
 	ConfigParser = engine.ConfigParser
+	ParserOption = engine.ParserOption
+	ExtendsList  = engine.ExtendsList
+	ProfileConfig = engine.ProfileConfig
+	InputConfig   = engine.InputConfig
+	InputType     = engine.InputType
+	ConfigDiff    = engine.ConfigDiff
+	ConfigChange  = engine.ConfigChange
+
+	// Variable sources
+	VariableSource     = engine.VariableSource
+	VariableSourceFunc = engine.VariableSourceFunc
+
+	// Secret types
+	Secret             = engine.Secret
+	SecretProvider     = engine.SecretProvider
+	SecretProviderFunc = engine.SecretProviderFunc
+	EnvSecretProvider  = engine.EnvSecretProvider
+	FileSecretProvider = engine.FileSecretProvider
+
+	// Schema types
+	Schema         = engine.Schema
+	SchemaType     = engine.SchemaType
+	SchemaProvider = engine.SchemaProvider
 )
 
 // Re-export constants
@@ -56,14 +97,90 @@ const (
 	SerialMode   = engine.SerialMode
 	ParallelMode = engine.ParallelMode
 	AsyncMode    = engine.AsyncMode
+
+	InputTypeAny      = engine.InputTypeAny
+	InputTypeString   = engine.InputTypeString
+	InputTypeNumber   = engine.InputTypeNumber
+	InputTypeInteger  = engine.InputTypeInteger
+	InputTypeBoolean  = engine.InputTypeBoolean
+	InputTypeObject   = engine.InputTypeObject
+	InputTypeArray    = engine.InputTypeArray
+	InputTypeDuration = engine.InputTypeDuration
+
+	DataSchemaReject = engine.DataSchemaReject
+	DataSchemaWarn   = engine.DataSchemaWarn
 )
 
 // Re-export constructor functions
 var (
 	NewComponentRegistry = engine.NewComponentRegistry
+	DefaultComponents    = engine.DefaultComponents
+	RegisterComponent    = engine.RegisterComponent
+	MustRegisterComponent = engine.MustRegisterComponent
 	NewEngine            = engine.NewEngine
 	NewLayer             = engine.NewLayer
 	NewConfigParser      = engine.NewConfigParser
 	NewDataContext     = engine.NewDataContext
 	NewDataContextWith = engine.NewDataContextWith
-)
\ No newline at end of file
+	BoolPtr            = engine.BoolPtr
+	WithStrictMode     = engine.WithStrictMode
+	WithSchemaValidation = engine.WithSchemaValidation
+	GenerateConfigSchema = engine.GenerateConfigSchema
+	DecodeConfigMap      = engine.DecodeConfigMap
+	ComponentFunc        = engine.ComponentFunc
+	FactoryFunc          = engine.FactoryFunc
+	DiffConfigs          = engine.DiffConfigs
+	AnalyzeDataFlow      = engine.AnalyzeDataFlow
+	GetInt               = engine.GetInt
+	GetInt64             = engine.GetInt64
+	GetFloat             = engine.GetFloat
+	GetBool              = engine.GetBool
+	GetDuration          = engine.GetDuration
+	GetBytes             = engine.GetBytes
+	GetMap               = engine.GetMap
+	SnapshotScopes       = engine.SnapshotScopes
+	NewTrackedDataContext     = engine.NewTrackedDataContext
+	NewTrackedDataContextWith = engine.NewTrackedDataContextWith
+	DataHistory               = engine.DataHistory
+	MarshalDataContext        = engine.MarshalDataContext
+	UnmarshalDataContext      = engine.UnmarshalDataContext
+	RestoreDataContext        = engine.RestoreDataContext
+	DeepSnapshot              = engine.DeepSnapshot
+	NewDataTypeRegistry       = engine.NewDataTypeRegistry
+	RegisterDataType          = engine.RegisterDataType
+	WithDataCodec             = engine.WithDataCodec
+	WithDataTypes             = engine.WithDataTypes
+	JSONCodec                 = engine.JSONCodec
+	GobCodec                  = engine.GobCodec
+	ContextWithExecutionInfo  = engine.ContextWithExecutionInfo
+	ExecutionInfoFromContext  = engine.ExecutionInfoFromContext
+	ComponentScopeName   = engine.ComponentScopeName
+	WithDataScopes       = engine.WithDataScopes
+	WithBlobStorage      = engine.WithBlobStorage
+	WithTransactionalData = engine.WithTransactionalData
+	NewBlobStore         = engine.NewBlobStore
+	AttachBlobStore      = engine.AttachBlobStore
+	BlobStoreFrom        = engine.BlobStoreFrom
+	SetBlob              = engine.SetBlob
+	RunCleanups          = engine.RunCleanups
+	NewDataSchemaRegistry = engine.NewDataSchemaRegistry
+	AttachDataSchemas    = engine.AttachDataSchemas
+	DataViolations       = engine.DataViolations
+	WithDataSchemas      = engine.WithDataSchemas
+	WithProfile          = engine.WithProfile
+	WithVariableSource   = engine.WithVariableSource
+	EnvSource            = engine.EnvSource
+	MapSource            = engine.MapSource
+	FileSource           = engine.FileSource
+	DirSource            = engine.DirSource
+	ChainSources         = engine.ChainSources
+	NewEnvSecretProvider  = engine.NewEnvSecretProvider
+	NewFileSecretProvider = engine.NewFileSecretProvider
+	ConfigString          = engine.ConfigString
+)
+
+// ProfileEnvVar 用于选择 profile 的环境变量
+const ProfileEnvVar = engine.ProfileEnvVar
+
+// SecretRefPrefix 组件配置中引用密钥的前缀
+const SecretRefPrefix = engine.SecretRefPrefix
\ No newline at end of file